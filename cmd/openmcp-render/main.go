@@ -0,0 +1,34 @@
+// openmcp-render writes the manifests of an openMCP setup file in install order to a directory
+// without creating any cluster
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/setup"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	file := flag.String("f", "", "setup file in YAML or JSON format")
+	dir := flag.String("o", "manifests", "directory the manifests are written to, existing YAML files are removed")
+	flag.Parse()
+	if *file == "" {
+		klog.Error("-f must be set")
+		klog.Flush()
+		os.Exit(2)
+	}
+	s, err := setup.LoadOpenMCPSetup(*file)
+	if err == nil {
+		err = s.DryRun(context.Background(), *dir)
+	}
+	if err != nil {
+		klog.Error(err)
+		klog.Flush()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,31 @@
+// openmcp-sweep deletes the kind clusters of openMCP test runs that have finished or have been interrupted
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/setup"
+)
+
+func main() {
+	opts := setup.SweepOptions{}
+	klog.InitFlags(nil)
+	flag.StringVar(&opts.StateDir, "state-dir", "", "directory the run states are recorded in (default $"+setup.StateDirEnvVar+" or a directory in the temp dir)")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "only print the kind clusters that would be deleted")
+	flag.BoolVar(&opts.IncludeKept, "include-kept", false, "also delete environments that have been kept for reuse")
+	flag.BoolVar(&opts.IncludeUnknown, "include-unknown", false, "also delete platform clusters without a recorded run state and the clusters of their runs")
+	flag.Parse()
+	deleted, err := setup.Sweep(opts)
+	for _, name := range deleted {
+		fmt.Println(name)
+	}
+	if err != nil {
+		klog.Error(err)
+		klog.Flush()
+		os.Exit(1)
+	}
+}
@@ -33,6 +33,7 @@ func TestMain(m *testing.M) {
 				Image: "ghcr.io/openmcp-project/images/cluster-provider-kind:v0.4.1",
 			},
 		},
+		PlatformClusterProvider: "kind",
 		ServiceProviders: []providers.ServiceProviderSetup{
 			{
 				Name:  "crossplane",
@@ -51,8 +52,8 @@ func TestMain(m *testing.M) {
 		},
 	}
 	testenv = env.NewWithConfig(envconf.New().WithNamespace(openmcp.Namespace))
-	openmcp.Bootstrap(testenv)
-	os.Exit(testenv.Run(m))
+	environment := openmcp.Bootstrap(testenv)
+	os.Exit(environment.Run(testenv, m))
 }
 
 func initLogging() {
@@ -12,8 +12,8 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/features"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
-	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
 	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup"
 )
 
 func TestServiceProvider(t *testing.T) {
@@ -22,7 +22,7 @@ func TestServiceProvider(t *testing.T) {
 		Setup(providers.ImportServiceProviderAPIs("serviceproviderobjects", wait.WithTimeout(time.Minute))).
 		Setup(providers.ImportDomainAPIs("test-mcp", "domainobjects", wait.WithTimeout(time.Minute))).
 		Assess("verify onboarding cluster objects", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
-			cfg, err := clusterutils.OnboardingConfig()
+			cfg, err := openmcpEnvironment(ctx, t).OnboardingConfig()
 			if err != nil {
 				t.Error(err)
 				return ctx
@@ -31,7 +31,7 @@ func TestServiceProvider(t *testing.T) {
 			return ctx
 		}).
 		Assess("verify mcp cluster objects", func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
-			cfg, err := clusterutils.MCPConfig(ctx, c, "test-mcp")
+			cfg, err := openmcpEnvironment(ctx, t).MCPConfig(ctx, "test-mcp")
 			if err != nil {
 				t.Error(err)
 				return ctx
@@ -57,6 +57,14 @@ func TestServiceProvider(t *testing.T) {
 	testenv.Test(t, basicProviderTest.Feature())
 }
 
+func openmcpEnvironment(ctx context.Context, t *testing.T) *setup.OpenMCPEnvironment {
+	e, ok := setup.EnvironmentFromContext(ctx)
+	if !ok {
+		t.Fatal("openmcp environment not found in context")
+	}
+	return e
+}
+
 func assertDummyConfigMap(ctx context.Context, t *testing.T, cfg *envconf.Config) {
 	cm := &corev1.ConfigMap{}
 	if err := cfg.Client().Resources().Get(ctx, "dummy", corev1.NamespaceDefault, cm); err != nil {
@@ -2,14 +2,16 @@ package internal
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -55,7 +57,7 @@ func ToUnstructured(obj k8s.Object) (*unstructured.Unstructured, error) {
 
 // IgnoreNotFound returns returns no error for IsNotFound
 func IgnoreNotFound(err error) error {
-	if errors.IsNotFound(err) {
+	if apierrors.IsNotFound(err) {
 		return nil
 	}
 	return err
@@ -70,6 +72,18 @@ func UnstructuredRef(name string, namespace string, gvk schema.GroupVersionKind)
 	return obj
 }
 
+// CheckImage returns an error if spec.image of the passed in object differs from the expected image
+func CheckImage(obj *unstructured.Unstructured, image string) error {
+	actual, _, err := unstructured.NestedString(obj.Object, "spec", "image")
+	if err != nil {
+		return err
+	}
+	if actual != image {
+		return fmt.Errorf("%s %s: expected image %s, found %s", obj.GetKind(), obj.GetName(), image, actual)
+	}
+	return nil
+}
+
 // MustTmpFileFromEmbedFS creates a temporary file from an embedded file and returns the file path
 func MustTmpFileFromEmbedFS(fs embed.FS, path string) string {
 	data, err := fs.ReadFile(path)
@@ -88,3 +102,25 @@ func MustTmpFileFromEmbedFS(fs embed.FS, path string) string {
 	}
 	return tmpPath
 }
+
+// RunConcurrently runs the passed in funcs with at most limit funcs at a time and returns their errors
+// joined in the order of the funcs. A limit lower than one runs all funcs at once.
+func RunConcurrently(limit int, funcs ...func() error) error {
+	if limit < 1 {
+		limit = len(funcs)
+	}
+	errs := make([]error, len(funcs))
+	sem := make(chan struct{}, max(limit, 1))
+	wg := sync.WaitGroup{}
+	for i, fn := range funcs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -4,8 +4,11 @@ import (
 	"bufio"
 	"bytes"
 	"embed"
+	"errors"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
 	"github.com/stretchr/testify/assert"
@@ -80,3 +83,29 @@ func TestExecTemplateFile(t *testing.T) {
 		})
 	}
 }
+
+func TestRunConcurrently(t *testing.T) {
+	errFirst := errors.New("first")
+	errThird := errors.New("third")
+	var running, maxRunning atomic.Int32
+	fn := func(err error) func() error {
+		return func() error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return err
+		}
+	}
+	err := internal.RunConcurrently(2, fn(errFirst), fn(nil), fn(errThird), fn(nil))
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, errThird)
+	assert.Equal(t, "first\nthird", err.Error())
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+	assert.NoError(t, internal.RunConcurrently(0))
+}
@@ -26,9 +26,35 @@ import (
 
 var errClusterNotFound = errors.New("cluster not found")
 
+// RunIDLabel is the label that identifies the test run a Cluster object belongs to
+const RunIDLabel = "testing.openmcp.cloud/run-id"
+
+// runID is the ID of the current test run, see SetRunID
+var runID string
+
+// SetRunID limits all cluster lookups to kind clusters that belong to the test run with the passed in ID.
+// A kind cluster belongs to a run if its name ends with "-<run ID>" or contains "-<run ID>-", see BelongsToRun.
+// An empty ID disables the limitation.
+func SetRunID(id string) {
+	runID = id
+}
+
+// RunID returns the ID of the current test run
+func RunID() string {
+	return runID
+}
+
+// BelongsToRun returns true if the kind cluster with the passed in name belongs to the run with the passed in ID.
+// The run ID has to be a complete name segment, i.e. the name ends with "-<run ID>" or contains "-<run ID>-",
+// so that the clusters of run abc don't match run ab.
+func BelongsToRun(clusterName string, id string) bool {
+	return id == "" || strings.HasSuffix(clusterName, "-"+id) || strings.Contains(clusterName, "-"+id+"-")
+}
+
 type ClusterProvider interface {
 	KubeConfig(string, bool) (string, error)
 	List() ([]string, error)
+	Delete(string, string) error
 }
 
 var clusterProvider = func() ClusterProvider {
@@ -44,18 +70,20 @@ var listResources = func(c *envconf.Config) ListResources {
 }
 
 // ConfigByPrefix returns an environment Config with the passed in namespace and
-// a klient that is set up to interact with the cluster identified by the passed
-// in cluster name prefix
+// a klient that is set up to interact with the cluster of the current run identified
+// by the passed in cluster name prefix
 func ConfigByPrefix(prefix string, namespace string) (*envconf.Config, error) {
-	kind := clusterProvider()
-	clusterName, err := retrieveKindClusterNameByPrefix(prefix, kind)
+	clusterName, err := retrieveKindClusterNameByPrefix(prefix, clusterProvider())
 	if err != nil {
 		return nil, err
 	}
-	if clusterName == "" {
-		return nil, fmt.Errorf("prefix %s: %w", prefix, errClusterNotFound)
-	}
-	kubeConfig, err := kind.KubeConfig(clusterName, false)
+	return ConfigByName(clusterName, namespace)
+}
+
+// ConfigByName returns an environment Config with the passed in namespace and
+// a klient that is set up to interact with the kind cluster with the passed in name
+func ConfigByName(clusterName string, namespace string) (*envconf.Config, error) {
+	kubeConfig, err := clusterProvider().KubeConfig(clusterName, false)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +98,42 @@ func ConfigByPrefix(prefix string, namespace string) (*envconf.Config, error) {
 	return envconf.New().WithClient(client).WithNamespace(namespace), nil
 }
 
+// KubeConfig returns the kubeconfig of the kind cluster with the passed in name
+func KubeConfig(clusterName string) (string, error) {
+	return clusterProvider().KubeConfig(clusterName, false)
+}
+
+// ClusterNames returns the names of all kind clusters that belong to the current run
+func ClusterNames() ([]string, error) {
+	return RunClusterNames(runID)
+}
+
+// RunClusterNames returns the names of all kind clusters that belong to the run with the passed in ID.
+// An empty ID returns all kind clusters.
+func RunClusterNames(id string) ([]string, error) {
+	clusters, err := clusterProvider().List()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, clusterName := range clusters {
+		if BelongsToRun(clusterName, id) {
+			names = append(names, clusterName)
+		}
+	}
+	return names, nil
+}
+
+// DeleteCluster deletes the kind cluster with the passed in name
+func DeleteCluster(clusterName string) error {
+	return clusterProvider().Delete(clusterName, "")
+}
+
+// ClusterNameByPrefix returns the name of the first kind cluster of the current run whose name starts with the passed in prefix
+func ClusterNameByPrefix(prefix string) (string, error) {
+	return retrieveKindClusterNameByPrefix(prefix, clusterProvider())
+}
+
 // OnboardingConfig is a utility function to return an environment config to work
 // with the onboarding cluster and default namespace
 // In scenarios where you work with multiple onboarding clusters, use ConfigByPrefix instead
@@ -77,6 +141,12 @@ func OnboardingConfig() (*envconf.Config, error) {
 	return ConfigByPrefix("onboarding", corev1.NamespaceDefault)
 }
 
+// WorkloadConfig is a utility function to return an environment config to work
+// with the workload cluster and default namespace
+func WorkloadConfig() (*envconf.Config, error) {
+	return ConfigByPrefix("workload", corev1.NamespaceDefault)
+}
+
 // McpConfig is a utility function to return an environment config to work
 // with the mcp cluster and default namespace.
 func MCPConfig(ctx context.Context, platformCluster *envconf.Config, mcpName string) (*envconf.Config, error) {
@@ -118,11 +188,11 @@ func retrieveKindClusterNameByPrefix(prefix string, provider ClusterProvider) (s
 		return "", err
 	}
 	for _, clusterName := range clusters {
-		if strings.HasPrefix(clusterName, prefix) {
+		if strings.HasPrefix(clusterName, prefix) && BelongsToRun(clusterName, runID) {
 			return clusterName, nil
 		}
 	}
-	return "", fmt.Errorf("no cluster found with prefix %s", prefix)
+	return "", fmt.Errorf("prefix %s: %w", prefix, errClusterNotFound)
 }
 
 // ImportToPlatformCluster applies a set of resources from a directory to the platform cluster
@@ -52,6 +52,11 @@ func (f fakeClusterProvider) List() ([]string, error) {
 	return f.clusters, nil
 }
 
+// Delete implements [ClusterProvider].
+func (f fakeClusterProvider) Delete(string, string) error {
+	return nil
+}
+
 var _ ListResources = fakeListResources{}
 
 type fakeListResources struct {
@@ -130,3 +135,68 @@ func TestMCPConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		runID string
+		want  []string
+	}{
+		{
+			name:  "all clusters without run ID",
+			runID: "",
+			want:  []string{"platform-abc", "onboarding-abc", "platform-def", "workload-def-x1"},
+		},
+		{
+			name:  "only clusters of the run",
+			runID: "def",
+			want:  []string{"platform-def", "workload-def-x1"},
+		},
+		{
+			name:  "run ID is a prefix of another run ID",
+			runID: "de",
+			want:  []string{},
+		},
+		{
+			name:  "no clusters of the run",
+			runID: "xyz",
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterProvider = func() ClusterProvider {
+				return fakeClusterProvider{
+					clusters: []string{"platform-abc", "onboarding-abc", "platform-def", "workload-def-x1"},
+				}
+			}
+			SetRunID(tt.runID)
+			defer SetRunID("")
+			got, err := ClusterNames()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBelongsToRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName string
+		runID       string
+		want        bool
+	}{
+		{name: "no run ID matches every cluster", clusterName: "platform-abc", runID: "", want: true},
+		{name: "run ID at the end", clusterName: "platform-abc", runID: "abc", want: true},
+		{name: "run ID followed by a suffix", clusterName: "mcp-abc-x1f2", runID: "abc", want: true},
+		{name: "run ID is a prefix of another run ID", clusterName: "platform-abcdef", runID: "abc", want: false},
+		{name: "prefix of another run ID followed by a suffix", clusterName: "mcp-abcdef-x1f2", runID: "abc", want: false},
+		{name: "numeric suffix of another run ID", clusterName: "platform-e2e2", runID: "e2e", want: false},
+		{name: "run ID is a suffix of another run ID", clusterName: "platform-xabc", runID: "abc", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, BelongsToRun(tt.clusterName, tt.runID))
+		})
+	}
+}
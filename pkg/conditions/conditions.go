@@ -68,6 +68,28 @@ func Status(obj k8s.Object, cfg *envconf.Config, key string, value string) wait.
 	}
 }
 
+// ObservedGeneration returns true if status.observedGeneration of an object is at least the passed in generation.
+// Objects without status.observedGeneration satisfy the condition.
+// If an object is not found, the condition is not satisfied and no error is returned.
+func ObservedGeneration(obj k8s.Object, cfg *envconf.Config, generation int64) wait.ConditionWithContextFunc {
+	return func(ctx context.Context) (done bool, err error) {
+		klog.Infof("%s: waiting for observed generation %d", fmtObj(obj), generation)
+		err = cfg.Client().Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), obj)
+		if err != nil {
+			return false, internal.IgnoreNotFound(err)
+		}
+		u, err := internal.ToUnstructured(obj)
+		if err != nil {
+			return false, err
+		}
+		observed, found, err := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+		if err != nil || !found {
+			return !found, err
+		}
+		return observed >= generation, nil
+	}
+}
+
 func checkCondition(k8sobj k8s.Object, desiredType string, desiredStatus corev1.ConditionStatus) bool {
 	fmtobj := fmtObj(k8sobj)
 	u, err := internal.ToUnstructured(k8sobj)
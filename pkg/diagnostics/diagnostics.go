@@ -0,0 +1,172 @@
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+// ArtifactsDirEnvVar is the environment variable that configures the artifacts directory if it is not set explicitly
+const ArtifactsDirEnvVar = "OPENMCP_ARTIFACTS_DIR"
+
+// openMCPResources are the openmcp resources that are dumped for every cluster. Resources
+// that are not known to a cluster are skipped.
+var openMCPResources = []schema.GroupVersionKind{
+	{Group: "clusters.openmcp.cloud", Version: "v1alpha1", Kind: "Cluster"},
+	{Group: "clusters.openmcp.cloud", Version: "v1alpha1", Kind: "ClusterRequest"},
+	{Group: "clusters.openmcp.cloud", Version: "v1alpha1", Kind: "AccessRequest"},
+	{Group: "openmcp.cloud", Version: "v1alpha1", Kind: "ClusterProvider"},
+	{Group: "openmcp.cloud", Version: "v1alpha1", Kind: "ServiceProvider"},
+	{Group: "openmcp.cloud", Version: "v1alpha1", Kind: "PlatformService"},
+	{Group: "core.open-control-plane.io", Version: "v2alpha1", Kind: "ControlPlane"},
+}
+
+// listResources and newClientset return the clients used to dump the objects and logs of a cluster
+var (
+	listResources = clusterResources
+	newClientset  = clientsetForConfig
+)
+
+func clusterResources(c *envconf.Config) clusterutils.ListResources {
+	return c.Client().Resources()
+}
+
+func clientsetForConfig(c *envconf.Config) (kubernetes.Interface, error) {
+	return kubernetes.NewForConfig(c.Client().RESTConfig())
+}
+
+// ArtifactsDir returns dir or, if dir is empty, the value of OPENMCP_ARTIFACTS_DIR.
+// An empty result means that diagnostics collection is disabled.
+func ArtifactsDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	return os.Getenv(ArtifactsDirEnvVar)
+}
+
+// DirName converts a test or step name into a name that can be used as directory name
+func DirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// CollectAll dumps the diagnostics of the platform cluster and every other kind cluster into a subdirectory of dir per cluster.
+// The platform cluster is skipped if it has not been created yet. Errors are collected and do not stop the collection.
+func CollectAll(ctx context.Context, platformCluster *envconf.Config, platformClusterName string, dir string) error {
+	klog.Infof("collecting diagnostics into %s...", dir)
+	errs := []error{}
+	if platformCluster.KubeconfigFile() != "" {
+		errs = append(errs, Collect(ctx, platformCluster, filepath.Join(dir, platformClusterName)))
+	}
+	clusters, err := clusterutils.ClusterNames()
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to list clusters: %w", err))...)
+	}
+	for _, cluster := range clusters {
+		if cluster == platformClusterName {
+			continue
+		}
+		cfg, err := clusterutils.ConfigByName(cluster, corev1.NamespaceDefault)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster, err))
+			continue
+		}
+		errs = append(errs, Collect(ctx, cfg, filepath.Join(dir, cluster)))
+	}
+	return errors.Join(errs...)
+}
+
+// Collect dumps nodes, events, openmcp resources and the logs of all containers,
+// including init containers, of a single cluster into dir
+func Collect(ctx context.Context, c *envconf.Config, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	errs := []error{
+		dumpList(ctx, c, &corev1.NodeList{}, filepath.Join(dir, "nodes.yaml")),
+		dumpList(ctx, c, &corev1.EventList{}, filepath.Join(dir, "events.yaml")),
+		collectPodLogs(ctx, c, filepath.Join(dir, "pods")),
+	}
+	for _, gvk := range openMCPResources {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		path := filepath.Join(dir, "openmcp", strings.ToLower(gvk.Kind)+".yaml")
+		if err := dumpList(ctx, c, list, path); err != nil && !meta.IsNoMatchError(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func dumpList(ctx context.Context, c *envconf.Config, list k8s.ObjectList, path string) error {
+	if err := listResources(c).List(ctx, list); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func collectPodLogs(ctx context.Context, c *envconf.Config, dir string) error {
+	clientset, err := newClientset(c)
+	if err != nil {
+		return err
+	}
+	pods := &corev1.PodList{}
+	if err := listResources(c).List(ctx, pods); err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, pod := range pods.Items {
+		podDir := filepath.Join(dir, pod.Namespace, pod.Name)
+		if err := os.MkdirAll(podDir, 0o755); err != nil {
+			return err
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			errs = append(errs, writeLogs(ctx, clientset, pod, status.Name, false, filepath.Join(podDir, status.Name+".log")))
+			if status.RestartCount > 0 {
+				errs = append(errs, writeLogs(ctx, clientset, pod, status.Name, true, filepath.Join(podDir, status.Name+".previous.log")))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func writeLogs(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, container string, previous bool, path string) error {
+	logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	}).DoRaw(ctx)
+	if err != nil {
+		// containers that never started have no logs
+		klog.V(2).Infof("failed to get logs of %s/%s/%s: %v", pod.Namespace, pod.Name, container, err)
+		return nil
+	}
+	return os.WriteFile(path, logs, 0o644)
+}
@@ -0,0 +1,136 @@
+package diagnostics
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/k8s/resources"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+func TestDirName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "TestE2E", want: "TestE2E"},
+		{name: "TestE2E/create mcp", want: "TestE2E_create_mcp"},
+		{name: `feature: a\b*c?"d"<e>|f`, want: "feature__a_b_c__d__e__f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DirName(tt.name))
+		})
+	}
+}
+
+func TestArtifactsDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		dir    string
+		envDir string
+		want   string
+	}{
+		{name: "disabled"},
+		{name: "environment variable", envDir: "/env", want: "/env"},
+		{name: "field takes precedence", dir: "/field", envDir: "/env", want: "/field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ArtifactsDirEnvVar, tt.envDir)
+			assert.Equal(t, tt.want, ArtifactsDir(tt.dir))
+		})
+	}
+}
+
+var _ clusterutils.ListResources = fakeCluster{}
+
+// fakeCluster lists nodes, pods and openmcp Clusters, the other openmcp resources are unknown
+type fakeCluster struct {
+	nodes    []corev1.Node
+	pods     []corev1.Pod
+	clusters []unstructured.Unstructured
+}
+
+// List implements [clusterutils.ListResources].
+func (f fakeCluster) List(_ context.Context, objs k8s.ObjectList, _ ...resources.ListOption) error {
+	switch list := objs.(type) {
+	case *corev1.NodeList:
+		list.Items = f.nodes
+	case *corev1.PodList:
+		list.Items = f.pods
+	case *unstructured.UnstructuredList:
+		if list.GetKind() != "Cluster" {
+			return &meta.NoKindMatchError{GroupKind: list.GroupVersionKind().GroupKind()}
+		}
+		list.Items = f.clusters
+	}
+	return nil
+}
+
+func TestCollect(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "openmcp-system"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "init"}},
+			ContainerStatuses:     []corev1.ContainerStatus{{Name: "manager", RestartCount: 1}},
+		},
+	}
+	cluster := unstructured.Unstructured{}
+	cluster.SetAPIVersion("clusters.openmcp.cloud/v1alpha1")
+	cluster.SetKind("Cluster")
+	cluster.SetName("onboarding")
+	listResources = func(*envconf.Config) clusterutils.ListResources {
+		return fakeCluster{
+			nodes:    []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "platform-control-plane"}}},
+			pods:     []corev1.Pod{pod},
+			clusters: []unstructured.Unstructured{cluster},
+		}
+	}
+	newClientset = func(*envconf.Config) (kubernetes.Interface, error) {
+		return fake.NewClientset(&pod), nil
+	}
+	defer func() {
+		listResources, newClientset = clusterResources, clientsetForConfig
+	}()
+
+	dir := t.TempDir()
+	require.NoError(t, Collect(context.Background(), envconf.New(), dir))
+	files := []string{}
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, rel)
+		return err
+	}))
+	assert.ElementsMatch(t, []string{
+		"nodes.yaml",
+		"events.yaml",
+		"openmcp/cluster.yaml",
+		"pods/openmcp-system/operator/init.log",
+		"pods/openmcp-system/operator/manager.log",
+		"pods/openmcp-system/operator/manager.previous.log",
+	}, files)
+	nodes, err := os.ReadFile(filepath.Join(dir, "nodes.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(nodes), "name: platform-control-plane")
+	clusters, err := os.ReadFile(filepath.Join(dir, "openmcp", "cluster.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clusters), "name: onboarding")
+}
@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/e2e-framework/klient/wait"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 
+	"github.com/openmcp-project/openmcp-testing/internal"
 	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
 	"github.com/openmcp-project/openmcp-testing/pkg/conditions"
 	"github.com/openmcp-project/openmcp-testing/pkg/resources"
@@ -73,6 +74,21 @@ func InstallPlatformService(ctx context.Context, c *envconf.Config, ps PlatformS
 	return wait.For(conditions.Match(obj, c, "Ready", corev1.ConditionTrue), ps.WaitOpts...)
 }
 
+// RenderPlatformService returns the manifest of the platform service object created by InstallPlatformService
+func RenderPlatformService(ps PlatformServiceSetup) (string, error) {
+	return internal.ExecTemplate(platformServiceTemplate, ps)
+}
+
+// VerifyPlatformService checks that an already installed platform service runs the expected image and waits until it is ready
+func VerifyPlatformService(ctx context.Context, c *envconf.Config, ps PlatformServiceSetup) error {
+	klog.Infof("verify platform service: %s", ps.Name)
+	obj := platformServiceRef(ps.Name)
+	if err := wait.For(conditions.Match(obj, c, "Ready", corev1.ConditionTrue), ps.WaitOpts...); err != nil {
+		return err
+	}
+	return internal.CheckImage(obj, ps.Image)
+}
+
 // DeletePlatformService deletes the platform service object on the platform cluster and waits until the object has been deleted
 func DeletePlatformService(ctx context.Context, c *envconf.Config, name string, opts ...wait.Option) error {
 	klog.Infof("delete platform service: %s", name)
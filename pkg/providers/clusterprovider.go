@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
@@ -17,6 +18,7 @@ import (
 	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openmcp-project/openmcp-testing/internal"
 	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
@@ -63,9 +65,9 @@ type ClusterProviderSetup struct {
 
 // ClusterPurposeMapping is used to configure the openmcp-operator cluster scheduler
 type ClusterPurposeMapping struct {
-	Purpose string
-	Profile string
-	Tenancy clustersv1alpha1.Tenancy
+	Purpose string                   `json:"purpose"`
+	Profile string                   `json:"profile"`
+	Tenancy clustersv1alpha1.Tenancy `json:"tenancy"`
 }
 
 func mcpRef(ref types.NamespacedName) *unstructured.Unstructured {
@@ -117,13 +119,10 @@ func InstallClusterProvider(ctx context.Context, c *envconf.Config, clusterProvi
 	klog.Infof("create cluster provider %s", clusterProvider.Name)
 	if clusterProvider.DeploymentSpec != nil {
 		// create cluster provider based on deployment spec instead of template
-		if err := providerv1alpha1.AddToScheme(c.Client().Resources().GetScheme()); err != nil {
+		if err := addProviderScheme(c); err != nil {
 			return fmt.Errorf("failed to add provider scheme: %w", err)
 		}
-		cp := &providerv1alpha1.ClusterProvider{}
-		cp.Name = clusterProvider.Name
-		cp.Spec.DeploymentSpec = *clusterProvider.DeploymentSpec
-		cp.Spec.Image = clusterProvider.Image
+		cp := clusterProviderFromDeploymentSpec(clusterProvider)
 		if err := c.Client().Resources().Create(ctx, cp); err != nil {
 			return fmt.Errorf("failed to install ClusterProvider based on DeploymentSpec: %w", err)
 		}
@@ -136,6 +135,53 @@ func InstallClusterProvider(ctx context.Context, c *envconf.Config, clusterProvi
 	return wait.For(openmcpconditions.Match(obj, c, "Ready", corev1.ConditionTrue), clusterProvider.WaitOpts...)
 }
 
+// RenderClusterProvider returns the manifest of the cluster provider object created by InstallClusterProvider
+func RenderClusterProvider(clusterProvider ClusterProviderSetup) (string, error) {
+	if clusterProvider.DeploymentSpec == nil {
+		return internal.ExecTemplate(clusterProviderTemplate, clusterProvider)
+	}
+	cp := clusterProviderFromDeploymentSpec(clusterProvider)
+	cp.SetGroupVersionKind(providerv1alpha1.GroupVersion.WithKind("ClusterProvider"))
+	obj, err := internal.ToUnstructured(cp)
+	if err != nil {
+		return "", err
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "status")
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func clusterProviderFromDeploymentSpec(clusterProvider ClusterProviderSetup) *providerv1alpha1.ClusterProvider {
+	cp := &providerv1alpha1.ClusterProvider{}
+	cp.Name = clusterProvider.Name
+	cp.Spec.DeploymentSpec = *clusterProvider.DeploymentSpec
+	cp.Spec.Image = clusterProvider.Image
+	return cp
+}
+
+// schemeMu serializes scheme registrations of concurrent installations
+var schemeMu sync.Mutex
+
+func addProviderScheme(c *envconf.Config) error {
+	schemeMu.Lock()
+	defer schemeMu.Unlock()
+	return providerv1alpha1.AddToScheme(c.Client().Resources().GetScheme())
+}
+
+// VerifyClusterProvider checks that an already installed cluster provider runs the expected image and waits until it is ready
+func VerifyClusterProvider(ctx context.Context, c *envconf.Config, clusterProvider ClusterProviderSetup) error {
+	klog.Infof("verify cluster provider %s", clusterProvider.Name)
+	obj := clusterProviderRef(clusterProvider.Name)
+	if err := wait.For(openmcpconditions.Match(obj, c, "Ready", corev1.ConditionTrue), clusterProvider.WaitOpts...); err != nil {
+		return err
+	}
+	return internal.CheckImage(obj, clusterProvider.Image)
+}
+
 // DeleteClusterProvider deletes the cluster provider object and waits until the object has been deleted
 func DeleteClusterProvider(ctx context.Context, c *envconf.Config, name string, opts ...wait.Option) error {
 	klog.Infof("delete cluster provider: %s", name)
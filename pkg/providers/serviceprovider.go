@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/features"
 
+	"github.com/openmcp-project/openmcp-testing/internal"
 	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
 	"github.com/openmcp-project/openmcp-testing/pkg/conditions"
 	"github.com/openmcp-project/openmcp-testing/pkg/resources"
@@ -56,6 +57,21 @@ func InstallServiceProvider(ctx context.Context, c *envconf.Config, sp ServicePr
 	return wait.For(conditions.Match(obj, c, "Ready", corev1.ConditionTrue), sp.WaitOpts...)
 }
 
+// RenderServiceProvider returns the manifest of the service provider object created by InstallServiceProvider
+func RenderServiceProvider(sp ServiceProviderSetup) (string, error) {
+	return internal.ExecTemplate(serviceProviderTemplate, sp)
+}
+
+// VerifyServiceProvider checks that an already installed service provider runs the expected image and waits until it is ready
+func VerifyServiceProvider(ctx context.Context, c *envconf.Config, sp ServiceProviderSetup) error {
+	klog.Infof("verify service provider: %s", sp.Name)
+	obj := serviceProviderRef(sp.Name)
+	if err := wait.For(conditions.Match(obj, c, "Ready", corev1.ConditionTrue), sp.WaitOpts...); err != nil {
+		return err
+	}
+	return internal.CheckImage(obj, sp.Image)
+}
+
 // ImportServiceProviderAPIs iterates over each resource from the passed in directory
 // and applies it to the onboarding cluster
 func ImportServiceProviderAPIs(directory string, opts ...wait.Option) features.Func {
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+	"github.com/openmcp-project/openmcp-testing/pkg/conditions"
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+)
+
+type snapshotContextKey string
+
+var clusterGVK = schema.GroupVersionKind{
+	Group:   "clusters.openmcp.cloud",
+	Version: "v1alpha1",
+	Kind:    "Cluster",
+}
+
+// UpgradeServiceProvider changes the image of an installed service provider and waits until the new image
+// is rolled out and the service provider is ready
+func UpgradeServiceProvider(ctx context.Context, c *envconf.Config, sp ServiceProviderSetup) error {
+	klog.Infof("upgrade service provider %s to %s", sp.Name, sp.Image)
+	return upgrade(ctx, c, serviceProviderRef(sp.Name), sp.Image, sp.WaitOpts...)
+}
+
+// UpgradeClusterProvider changes the image of an installed cluster provider and waits until the new image
+// is rolled out and the cluster provider is ready
+func UpgradeClusterProvider(ctx context.Context, c *envconf.Config, cp ClusterProviderSetup) error {
+	klog.Infof("upgrade cluster provider %s to %s", cp.Name, cp.Image)
+	return upgrade(ctx, c, clusterProviderRef(cp.Name), cp.Image, cp.WaitOpts...)
+}
+
+func upgrade(ctx context.Context, c *envconf.Config, obj *unstructured.Unstructured, image string, opts ...wait.Option) error {
+	if err := c.Client().Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), obj); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, image, "spec", "image"); err != nil {
+		return err
+	}
+	if err := c.Client().Resources().Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update image: %w", err)
+	}
+	if err := wait.For(conditions.ObservedGeneration(obj, c, obj.GetGeneration()), opts...); err != nil {
+		return err
+	}
+	if err := resources.WaitForRollout(ctx, c, image, opts...); err != nil {
+		return err
+	}
+	return wait.For(conditions.Match(obj, c, "Ready", corev1.ConditionTrue), opts...)
+}
+
+// RecordClusters records the Cluster objects of the platform cluster, see VerifyClustersNotRecreated
+func RecordClusters() features.Func {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		snapshot, err := resources.TakeSnapshot(ctx, c, clusterGVK)
+		if err != nil {
+			t.Errorf("failed to record clusters: %v", err)
+			return ctx
+		}
+		klog.Infof("recorded %d clusters", len(snapshot))
+		return context.WithValue(ctx, snapshotContextKey("clusters"), snapshot)
+	}
+}
+
+// VerifyClustersNotRecreated checks that every Cluster object recorded by RecordClusters still exists
+// and has not been recreated
+func VerifyClustersNotRecreated() features.Func {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		verifySnapshot(ctx, t, c, "clusters")
+		return ctx
+	}
+}
+
+// RecordMCPObjects records the objects of the passed in kinds on the cluster of an MCP, see VerifyMCPObjectsSurvived
+func RecordMCPObjects(mcpName string, gvks ...schema.GroupVersionKind) features.Func {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		mcpCfg, err := clusterutils.MCPConfig(ctx, c, mcpName)
+		if err != nil {
+			t.Error(err)
+			return ctx
+		}
+		snapshot, err := resources.TakeSnapshot(ctx, mcpCfg, gvks...)
+		if err != nil {
+			t.Errorf("failed to record objects of MCP %s: %v", mcpName, err)
+			return ctx
+		}
+		klog.Infof("recorded %d objects of MCP %s", len(snapshot), mcpName)
+		return context.WithValue(ctx, snapshotContextKey("mcp/"+mcpName), snapshot)
+	}
+}
+
+// VerifyMCPObjectsSurvived checks that every object recorded by RecordMCPObjects still exists
+// and has not been recreated
+func VerifyMCPObjectsSurvived(mcpName string) features.Func {
+	return func(ctx context.Context, t *testing.T, c *envconf.Config) context.Context {
+		mcpCfg, err := clusterutils.MCPConfig(ctx, c, mcpName)
+		if err != nil {
+			t.Error(err)
+			return ctx
+		}
+		verifySnapshot(ctx, t, mcpCfg, "mcp/"+mcpName)
+		return ctx
+	}
+}
+
+func verifySnapshot(ctx context.Context, t *testing.T, c *envconf.Config, key string) {
+	snapshot, ok := ctx.Value(snapshotContextKey(key)).(resources.Snapshot)
+	if !ok {
+		t.Errorf("no objects recorded for %s", key)
+		return
+	}
+	if err := snapshot.Verify(ctx, c); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,163 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/conditions"
+)
+
+// DecodeManifest decodes multi-document YAML or JSON into objects. Empty documents are skipped.
+func DecodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	d := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	objs := []*unstructured.Unstructured{}
+	for {
+		raw := json.RawMessage{}
+		if err := d.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || string(trimmed) == "null" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// CreateObjects creates the objects in order and ignores objects that already exist. CustomResourceDefinitions
+// and Namespaces are created first, the remaining objects once all CustomResourceDefinitions are established.
+// Namespaced objects without namespace are created in the passed in namespace.
+func CreateObjects(ctx context.Context, cfg *envconf.Config, namespace string, objs []*unstructured.Unstructured, options ...wait.Option) error {
+	crds := slices.DeleteFunc(slices.Clone(objs), func(obj *unstructured.Unstructured) bool { return !isCRD(obj) })
+	namespaces := slices.DeleteFunc(slices.Clone(objs), func(obj *unstructured.Unstructured) bool { return !isNamespace(obj) })
+	others := slices.DeleteFunc(slices.Clone(objs), func(obj *unstructured.Unstructured) bool {
+		return isCRD(obj) || isNamespace(obj)
+	})
+	for _, obj := range slices.Concat(crds, namespaces) {
+		if err := createObject(ctx, cfg, obj); err != nil {
+			return err
+		}
+	}
+	for _, crd := range crds {
+		if err := wait.For(conditions.Match(crd, cfg, "Established", corev1.ConditionTrue), options...); err != nil {
+			return fmt.Errorf("CustomResourceDefinition %s not established: %w", crd.GetName(), err)
+		}
+	}
+	for _, obj := range others {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		if err := createObject(ctx, cfg, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createObject(ctx context.Context, cfg *envconf.Config, obj *unstructured.Unstructured) error {
+	klog.Infof("creating object (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	if err := cfg.Client().Resources().Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind().String() == "CustomResourceDefinition.apiextensions.k8s.io"
+}
+
+func isNamespace(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind().String() == "Namespace"
+}
+
+// WaitForWorkloads waits until all Deployments of the objects are available and all StatefulSets
+// and DaemonSets are ready
+func WaitForWorkloads(ctx context.Context, cfg *envconf.Config, objs []*unstructured.Unstructured, options ...wait.Option) error {
+	for _, obj := range objs {
+		ready, ok := workloadReadiness[obj.GroupVersionKind().GroupKind().String()]
+		if !ok {
+			continue
+		}
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := wait.For(func(ctx context.Context) (bool, error) {
+			if err := cfg.Client().Resources().Get(ctx, obj.GetName(), obj.GetNamespace(), current); err != nil {
+				return false, err
+			}
+			return ready(current), nil
+		}, options...); err != nil {
+			return fmt.Errorf("%s %s/%s not ready: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		klog.Infof("%s %s/%s ready", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return nil
+}
+
+// workloadReadiness contains a readiness check per workload kind
+var workloadReadiness = map[string]func(obj *unstructured.Unstructured) bool{
+	"Deployment.apps": func(obj *unstructured.Unstructured) bool {
+		return observed(obj) && conditionTrue(obj, "Available")
+	},
+	"StatefulSet.apps": func(obj *unstructured.Unstructured) bool {
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return observed(obj) && ready == replicas
+	},
+	"DaemonSet.apps": func(obj *unstructured.Unstructured) bool {
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		return observed(obj) && ready == desired
+	},
+}
+
+// observed returns true if the controller has observed the current generation of the object
+func observed(obj *unstructured.Unstructured) bool {
+	generation, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return generation >= obj.GetGeneration()
+}
+
+func conditionTrue(obj *unstructured.Unstructured, conditionType string) bool {
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conds {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == conditionType {
+			return cond["status"] == string(corev1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// DeleteObjects deletes the objects in reverse order without waiting for their deletion.
+// Objects that don't exist are ignored.
+func DeleteObjects(ctx context.Context, cfg *envconf.Config, objs []*unstructured.Unstructured) error {
+	errs := []error{}
+	for _, obj := range slices.Backward(objs) {
+		if err := cfg.Client().Resources().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), obj.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeManifest(t *testing.T) {
+	objs, err := DecodeManifest(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+---
+# only a comment
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: second
+spec:
+  replicas: 2
+`)
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	assert.Equal(t, "first", objs[0].GetName())
+	replicas, found, err := unstructured.NestedInt64(objs[1].Object, "spec", "replicas")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(2), replicas)
+}
+
+func TestWorkloadReadiness(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		object map[string]interface{}
+		want   bool
+	}{
+		{
+			name: "available deployment",
+			kind: "Deployment.apps",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"conditions":         []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "deployment with unobserved generation",
+			kind: "Deployment.apps",
+			object: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"conditions":         []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+				},
+			},
+		},
+		{
+			name: "statefulset defaults to one replica",
+			kind: "StatefulSet.apps",
+			object: map[string]interface{}{
+				"status": map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			want: true,
+		},
+		{
+			name: "statefulset not ready",
+			kind: "StatefulSet.apps",
+			object: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"readyReplicas": int64(2)},
+			},
+		},
+		{
+			name: "daemonset ready",
+			kind: "DaemonSet.apps",
+			object: map[string]interface{}{
+				"status": map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(3)},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, workloadReadiness[tt.kind](&unstructured.Unstructured{Object: tt.object}))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// WaitForRollout waits until at least one Deployment runs the passed in image and every Deployment
+// that runs the image has been rolled out completely
+func WaitForRollout(ctx context.Context, cfg *envconf.Config, image string, options ...wait.Option) error {
+	return wait.For(func(ctx context.Context) (bool, error) {
+		list := &appsv1.DeploymentList{}
+		if err := cfg.Client().Resources().List(ctx, list); err != nil {
+			return false, err
+		}
+		found := false
+		for _, d := range list.Items {
+			if !usesImage(d, image) {
+				continue
+			}
+			found = true
+			if !rolledOut(d) {
+				klog.Infof("Deployment %s/%s: waiting for rollout of %s", d.Namespace, d.Name, image)
+				return false, nil
+			}
+		}
+		if !found {
+			klog.Infof("waiting for a Deployment with image %s", image)
+		}
+		return found, nil
+	}, options...)
+}
+
+func usesImage(d appsv1.Deployment, image string) bool {
+	for _, c := range append(d.Spec.Template.Spec.InitContainers, d.Spec.Template.Spec.Containers...) {
+		if c.Image == image {
+			return true
+		}
+	}
+	return false
+}
+
+// rolledOut returns true if all replicas of a Deployment are updated and available and no old replicas are left
+func rolledOut(d appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.Replicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/internal"
+)
+
+// ObjectKey identifies an object of a snapshot
+type ObjectKey struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+}
+
+func (k ObjectKey) String() string {
+	if k.Namespace == "" {
+		return fmt.Sprintf("%s %s", k.GVK.Kind, k.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", k.GVK.Kind, k.Namespace, k.Name)
+}
+
+// Snapshot records the UIDs of objects to verify later, e.g. after an upgrade, that the objects
+// still exist and have not been recreated
+type Snapshot map[ObjectKey]types.UID
+
+// TakeSnapshot records all objects of the passed in kinds in all namespaces
+func TakeSnapshot(ctx context.Context, cfg *envconf.Config, gvks ...schema.GroupVersionKind) (Snapshot, error) {
+	snapshot := Snapshot{}
+	for _, gvk := range gvks {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := cfg.Client().Resources().List(ctx, list); err != nil {
+			return nil, fmt.Errorf("list %s: %w", gvk.Kind, err)
+		}
+		for _, item := range list.Items {
+			snapshot[ObjectKey{GVK: gvk, Namespace: item.GetNamespace(), Name: item.GetName()}] = item.GetUID()
+		}
+	}
+	return snapshot, nil
+}
+
+// Verify returns an error for every recorded object that has been deleted or recreated
+func (s Snapshot) Verify(ctx context.Context, cfg *envconf.Config) error {
+	current := Snapshot{}
+	for key := range s {
+		obj := internal.UnstructuredRef(key.Name, key.Namespace, key.GVK)
+		err := cfg.Client().Resources().Get(ctx, key.Name, key.Namespace, obj)
+		if internal.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err == nil {
+			current[key] = obj.GetUID()
+		}
+	}
+	return s.Compare(current)
+}
+
+// Compare returns an error for every object of the snapshot that is missing in the passed in snapshot
+// or has a different UID
+func (s Snapshot) Compare(current Snapshot) error {
+	keys := make([]ObjectKey, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	errs := []error{}
+	for _, key := range keys {
+		uid, ok := current[key]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%s has been deleted", key))
+		case uid != s[key]:
+			errs = append(errs, fmt.Errorf("%s has been recreated", key))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,20 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSnapshotCompare(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "clusters.openmcp.cloud", Version: "v1alpha1", Kind: "Cluster"}
+	onboarding := ObjectKey{GVK: gvk, Namespace: "openmcp-system", Name: "onboarding"}
+	platform := ObjectKey{GVK: gvk, Namespace: "openmcp-system", Name: "platform"}
+	mcp := ObjectKey{GVK: gvk, Namespace: "openmcp-system", Name: "mcp-x1"}
+	before := Snapshot{onboarding: "1", platform: "2", mcp: "3"}
+
+	assert.NoError(t, before.Compare(Snapshot{onboarding: "1", platform: "2", mcp: "3", {GVK: gvk, Name: "new"}: "4"}))
+	err := before.Compare(Snapshot{onboarding: "1", mcp: "5"})
+	assert.EqualError(t, err, "Cluster openmcp-system/mcp-x1 has been recreated\nCluster openmcp-system/platform has been deleted")
+}
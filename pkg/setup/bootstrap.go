@@ -3,11 +3,12 @@ package setup
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 
-	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1 "k8s.io/api/core/v1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/e2e-framework/klient/wait"
@@ -16,12 +17,12 @@ import (
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
 	"sigs.k8s.io/e2e-framework/pkg/types"
-	"sigs.k8s.io/e2e-framework/support/kind"
 
 	"github.com/openmcp-project/openmcp-testing/pkg/platformservices"
 	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
 
 	"github.com/openmcp-project/openmcp-testing/internal"
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
 	"github.com/openmcp-project/openmcp-testing/pkg/providers"
 	"github.com/openmcp-project/openmcp-testing/pkg/resources"
 )
@@ -37,6 +38,42 @@ type OpenMCPSetup struct {
 	PlatformServices []platformservices.PlatformServiceSetup
 	Extensions       []extensions.Extension
 	WaitOpts         []wait.Option
+	// PlatformCluster allows configuring the topology of the platform kind cluster
+	PlatformCluster KindClusterSetup
+	// PlatformClusterProvider is the name of the cluster provider that manages the platform cluster.
+	// It can be omitted if exactly one cluster provider is configured.
+	PlatformClusterProvider string
+	// ArtifactsDir is the directory diagnostics are collected into when a setup phase or a feature fails.
+	// If empty, the environment variable OPENMCP_ARTIFACTS_DIR is used. Collection is disabled if neither is set.
+	ArtifactsDir string
+	// RunID identifies the test run. It is part of the platform cluster name and the names and labels of all
+	// clusters created during the run, and limits all cluster lookups to the clusters of the run.
+	// If empty, the environment variable OPENMCP_RUN_ID is used or a random ID is generated.
+	RunID string
+	// Concurrency limits the number of images loaded and components of the same kind installed or deleted
+	// at a time. If zero, all of them are processed at once.
+	Concurrency int
+	// Registry configures an optional local OCI registry that serves the local images to all kind clusters of the run
+	Registry RegistrySetup
+	// Offline configures the pre-seeding of all images for environments without access to image registries
+	Offline OfflineSetup
+	// Preflight configures the checks that run before any cluster is created, see Preflight
+	Preflight PreflightSetup
+	// Teardown configures the teardown of the environment
+	Teardown TeardownSetup
+	// Hooks run before and after the phases of Bootstrap and of the teardown, see Hooks
+	Hooks Hooks
+	// ReportFile is the file the bootstrap report with the durations of all phases and component installations
+	// is written to. If empty, the environment variable OPENMCP_REPORT_FILE is used or, if an artifacts directory
+	// is configured, bootstrap-report.json in the artifacts directory. The summary table is always logged.
+	ReportFile string
+	// Reuse allows reusing an existing platform cluster from a previous run. If a ready environment is found,
+	// installation and teardown are skipped. Otherwise a new environment is set up and kept after the run.
+	// Reuse can also be enabled by setting the environment variable OPENMCP_REUSE=true.
+	Reuse bool
+
+	timings   *timings
+	installed *installedExtensions
 }
 
 type OpenMCPOperatorSetup struct {
@@ -50,60 +87,266 @@ type OpenMCPOperatorSetup struct {
 	LoadImageToCluster bool
 	// ExtraClusterPurposeMapping allows to provide additional cluster purpose mappings for the cluster scheduler
 	ExtraClusterPurposeMapping []providers.ClusterPurposeMapping
+	// Config is merged into the default operator configuration
+	Config *OperatorConfig
+	// RawConfig is merged into the operator configuration after Config and allows setting any configuration
+	// of the operator. Nested maps are merged, all other values are replaced.
+	RawConfig map[string]interface{}
+	// InitArgs are added to the args of the init container
+	InitArgs []string
+	// Args are added to the args of the operator container
+	Args []string
+	// Env is added to the environment variables of both containers
+	Env []corev1.EnvVar
+	// Resources are the resource requirements of both containers
+	Resources corev1.ResourceRequirements
+	// Replicas is the number of operator replicas, defaults to 1
+	Replicas int32
+	// ImagePullPolicy is the pull policy of the operator image
+	ImagePullPolicy corev1.PullPolicy
+	// ImagePullSecrets are used to pull the operator image
+	ImagePullSecrets []corev1.LocalObjectReference
+	// Verbosity is the log level of the operator, one of DEBUG, INFO or ERROR
+	Verbosity string
 }
 
-// Bootstrap sets up the minimum set of components of an openMCP installation and returns the platform cluster name
-func (s *OpenMCPSetup) Bootstrap(testenv env.Environment) string {
-	kindConfig := internal.MustTmpFileFromEmbedFS(configFS, "config/kind-config.yaml")
+// Bootstrap sets up the minimum set of components of an openMCP installation and returns a handle to the environment
+func (s *OpenMCPSetup) Bootstrap(testenv env.Environment) *OpenMCPEnvironment {
 	operatorTemplate := internal.MustTmpFileFromEmbedFS(configFS, "config/operator.yaml.tmpl")
-	platformClusterName := envconf.RandomName("platform", 16)
 	s.Operator.Namespace = s.Namespace
-	testenv.Setup(createPlatformCluster(platformClusterName, kindConfig)).
-		Setup(envfuncs.CreateNamespace(s.Namespace)).
-		Setup(s.loadImagesToCluster(platformClusterName)).
-		Setup(s.installOpenMCPOperator(operatorTemplate)).
-		Setup(s.installClusterProviders()).
-		Setup(s.managePlatformCluster(platformClusterName)).
-		Setup(s.installExtensions()).
-		Setup(s.verifyEnvironment()).
-		Setup(s.installPlatformServices()).
-		Setup(s.installServiceProviders()).
-		Finish(s.cleanup(kindConfig, operatorTemplate)).
-		Finish(envfuncs.DestroyCluster(platformClusterName))
-	return platformClusterName
+	runID, err := s.configuredRunID()
+	if err != nil {
+		return s.failedBootstrap(testenv, err, operatorTemplate)
+	}
+	clusterutils.SetRunID(runID)
+	reuse := s.reuseEnabled()
+	if reuse {
+		platformClusterName, found, err := findReusablePlatformCluster(runID, StateDir(""), processAlive)
+		if err != nil {
+			return s.failedBootstrap(testenv, err, operatorTemplate)
+		}
+		if found {
+			s.setRunID(runIDFromPlatformClusterName(platformClusterName))
+			klog.Infof("reusing platform cluster %s of run %s", platformClusterName, s.RunID)
+			environment := s.newEnvironment(platformClusterName)
+			r := startRun(s.RunID, true)
+			nodes := newNodeWatcher(s.RunID)
+			reg := newRegistry(s.Registry, nodes)
+			environment.registry = reg
+			seeder := newImageSeeder(s, nodes)
+			testenv.Setup(s.applyOverrides()).
+				Setup(s.preflight()).
+				Setup(s.validate()).
+				Setup(seeder.prepare()).
+				Setup(reg.start()).
+				Setup(s.createPlatformCluster(platformClusterName)).
+				Setup(environment.bind()).
+				Setup(reg.attach()).
+				Setup(nodes.start()).
+				Setup(s.reuseImages(reg)).
+				Setup(s.phase(PhaseVerification, platformClusterName, s.verifyReusedEnvironment())).
+				Setup(s.registerExtensionSchemes()).
+				Setup(environment.writeReport()).
+				BeforeEachFeature(recordFailureState()).
+				AfterEachFeature(s.collectDiagnosticsOnFailure(platformClusterName)).
+				Finish(environment.writeReport()).
+				Finish(removeTmpFiles(operatorTemplate)).
+				Finish(environment.cleanup()).
+				Finish(nodes.stopWatching()).
+				Finish(seeder.cleanup()).
+				Finish(environment.teardownStep("kind clusters", r.finish()))
+			return environment
+		}
+	}
+	if runID == "" {
+		runID = newRunID()
+	}
+	s.setRunID(runID)
+	klog.Infof("starting run %s", s.RunID)
+	platformClusterName := platformClusterName(s.RunID)
+	environment := s.newEnvironment(platformClusterName)
+	r := startRun(s.RunID, reuse)
+	nodes := newNodeWatcher(s.RunID)
+	reg := newRegistry(s.Registry, nodes)
+	environment.registry = reg
+	seeder := newImageSeeder(s, nodes)
+	testenv.Setup(s.applyOverrides()).
+		Setup(s.preflight()).
+		Setup(s.validate()).
+		Setup(seeder.prepare()).
+		Setup(s.phase(PhaseClusterCreation, platformClusterName, Compose(
+			reg.start(),
+			s.createPlatformCluster(platformClusterName),
+			environment.bind(),
+			reg.attach(),
+			envfuncs.CreateNamespace(s.Namespace)))).
+		Setup(s.phase(PhaseImageLoading, platformClusterName, Compose(
+			nodes.start(),
+			s.loadImagesToCluster(platformClusterName, reg)))).
+		Setup(s.phase(PhaseOperator, platformClusterName, s.installOpenMCPOperator(operatorTemplate))).
+		Setup(s.phase(PhaseClusterProviders, platformClusterName, s.installClusterProviders())).
+		Setup(s.phase(PhasePlatformCluster, platformClusterName, s.managePlatformCluster(platformClusterName))).
+		Setup(s.phase(PhaseExtensions, platformClusterName, s.installExtensions())).
+		Setup(s.phase(PhaseVerification, platformClusterName, s.verifyEnvironment())).
+		Setup(s.phase(PhasePlatformServices, platformClusterName, s.installPlatformServices())).
+		Setup(s.phase(PhaseServiceProviders, platformClusterName, s.installServiceProviders())).
+		Setup(environment.writeReport()).
+		BeforeEachFeature(recordFailureState()).
+		AfterEachFeature(s.collectDiagnosticsOnFailure(platformClusterName))
+	testenv.Finish(environment.writeReport()).
+		Finish(removeTmpFiles(operatorTemplate)).
+		Finish(environment.cleanup()).
+		Finish(nodes.stopWatching()).
+		Finish(seeder.cleanup())
+	if reuse {
+		klog.Infof("keeping platform cluster %s for reuse", platformClusterName)
+		testenv.Finish(environment.teardownStep("kind clusters", r.finish()))
+		return environment
+	}
+	testenv.Finish(environment.teardownStep("cleanup", s.cleanup())).
+		Finish(environment.teardownStep("platform cluster",
+			s.Hooks.withTeardownHooks(PhaseClusterCreation, destroyPlatformCluster(platformClusterName)))).
+		Finish(environment.teardownStep("kind clusters", r.finish(platformClusterPrefix, onboardingClusterPrefix)))
+	return environment
+}
+
+// failedBootstrap makes the first setup step fail with the passed in error, so that the suite fails before
+// any cluster is created, and removes the temporary files at the end of the run
+func (s *OpenMCPSetup) failedBootstrap(testenv env.Environment, err error, tmpFiles ...string) *OpenMCPEnvironment {
+	environment := s.newEnvironment("")
+	testenv.Setup(func(ctx context.Context, _ *envconf.Config) (context.Context, error) {
+		return ctx, err
+	}).
+		Finish(removeTmpFiles(tmpFiles...))
+	return environment
 }
 
-func createPlatformCluster(name string, kindConfig string) types.EnvFunc {
-	klog.Info("create platform cluster...")
-	return envfuncs.CreateClusterWithConfig(kind.NewProvider(), name, kindConfig)
+// setRunID sets the effective run ID and limits all cluster lookups to the clusters of the run
+func (s *OpenMCPSetup) setRunID(runID string) {
+	s.RunID = runID
+	clusterutils.SetRunID(runID)
+}
+
+func (s *OpenMCPSetup) newEnvironment(platformClusterName string) *OpenMCPEnvironment {
+	s.timings = newTimings(s.RunID)
+	s.installed = &installedExtensions{}
+	return &OpenMCPEnvironment{
+		RunID:               s.RunID,
+		PlatformClusterName: platformClusterName,
+		Namespace:           s.Namespace,
+		setup:               s,
+	}
 }
 
-func (s *OpenMCPSetup) cleanup(tmpFiles ...string) types.EnvFunc {
+// validate checks the setup before any cluster is created
+func (s *OpenMCPSetup) validate() types.EnvFunc {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if _, err := s.platformClusterProvider(); err != nil {
+			return ctx, err
+		}
+		if _, err := extensions.Sort(s.Extensions); err != nil {
+			return ctx, err
+		}
+		for _, ext := range s.Extensions {
+			for _, target := range extensions.TargetsOf(ext) {
+				if err := target.Validate(); err != nil {
+					return ctx, fmt.Errorf("extension %s: %w", ext.Name(), err)
+				}
+			}
+		}
+		return ctx, nil
+	}
+}
+
+func removeTmpFiles(tmpFiles ...string) types.EnvFunc {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
-		klog.Info("cleaning up environment...")
 		for _, f := range tmpFiles {
 			os.RemoveAll(f)
 		}
+		return ctx, nil
+	}
+}
+
+// cleanup deletes the components of the environment in reverse install order. All steps run, their errors are joined.
+func (s *OpenMCPSetup) cleanup() types.EnvFunc {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if c.KubeconfigFile() == "" {
+			// the platform cluster has not been created
+			return ctx, nil
+		}
+		klog.Info("cleaning up environment...")
+		steps := []struct {
+			phase Phase
+			fn    env.Func
+		}{
+			{PhaseServiceProviders, s.deleteServiceProviders()},
+			{PhasePlatformServices, s.deletePlatformServices()},
+			{PhaseExtensions, s.uninstallExtensions()},
+			{PhasePlatformCluster, s.deleteOnboardingCluster()},
+			{PhaseClusterProviders, s.deleteClusterProviders()},
+		}
+		errs := []error{}
+		for _, step := range steps {
+			var err error
+			ctx, err = s.Hooks.withTeardownHooks(step.phase, step.fn)(ctx, c)
+			errs = append(errs, err)
+		}
+		return ctx, errors.Join(errs...)
+	}
+}
+
+func (s *OpenMCPSetup) deleteServiceProviders() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		deletions := []func() error{}
 		for _, sp := range s.ServiceProviders {
-			if err := providers.DeleteServiceProvider(ctx, c, sp.Name, sp.WaitOpts...); err != nil {
-				klog.Errorf("delete service provider failed: %v", err)
-			}
+			deletions = append(deletions, func() error {
+				if err := providers.DeleteServiceProvider(ctx, c, sp.Name, sp.WaitOpts...); err != nil {
+					return fmt.Errorf("delete service provider %s failed: %w", sp.Name, err)
+				}
+				return nil
+			})
 		}
+		return ctx, internal.RunConcurrently(s.teardownLimit(), deletions...)
+	}
+}
+
+func (s *OpenMCPSetup) deletePlatformServices() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		deletions := []func() error{}
 		for _, ps := range s.PlatformServices {
-			if err := platformservices.DeletePlatformService(ctx, c, ps.Name); err != nil {
-				klog.Errorf("delete platform service failed: %v", err)
-			}
+			deletions = append(deletions, func() error {
+				if err := platformservices.DeletePlatformService(ctx, c, ps.Name); err != nil {
+					return fmt.Errorf("delete platform service %s failed: %w", ps.Name, err)
+				}
+				return nil
+			})
 		}
+		return ctx, internal.RunConcurrently(s.teardownLimit(), deletions...)
+	}
+}
+
+func (s *OpenMCPSetup) deleteOnboardingCluster() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
 		if err := providers.DeleteCluster(ctx, c, apimachinerytypes.NamespacedName{Namespace: s.Namespace, Name: "onboarding"},
 			s.WaitOpts...); err != nil {
-			klog.Errorf("delete cluster failed: %v", err)
+			return ctx, fmt.Errorf("delete cluster onboarding failed: %w", err)
 		}
+		return ctx, nil
+	}
+}
+
+func (s *OpenMCPSetup) deleteClusterProviders() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		deletions := []func() error{}
 		for _, cp := range s.ClusterProviders {
-			if err := providers.DeleteClusterProvider(ctx, c, cp.Name, cp.WaitOpts...); err != nil {
-				klog.Errorf("delete cluster provider failed: %v", err)
-			}
+			deletions = append(deletions, func() error {
+				if err := providers.DeleteClusterProvider(ctx, c, cp.Name, cp.WaitOpts...); err != nil {
+					return fmt.Errorf("delete cluster provider %s failed: %w", cp.Name, err)
+				}
+				return nil
+			})
 		}
-		return ctx, nil
+		return ctx, internal.RunConcurrently(s.teardownLimit(), deletions...)
 	}
 }
 
@@ -116,38 +359,19 @@ func (s *OpenMCPSetup) verifyEnvironment() types.EnvFunc {
 
 func (s *OpenMCPSetup) installOpenMCPOperator(tmpl string) types.EnvFunc {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
-		// default purpose mapping
-		purposeMapping := []providers.ClusterPurposeMapping{
-			{
-				Purpose: "mcp",
-				Profile: "kind",
-				Tenancy: "Exclusive",
-			},
-			{
-				Purpose: "platform",
-				Profile: "kind",
-				Tenancy: "Shared",
-			},
-			{
-				Purpose: "onboarding",
-				Profile: "kind",
-				Tenancy: "Shared",
-			},
-			{
-				Purpose: "workload",
-				Profile: "kind",
-				Tenancy: "Shared",
-			},
+		data, err := s.Operator.templateData(s.RunID)
+		if err != nil {
+			return ctx, err
 		}
-		s.Operator.ExtraClusterPurposeMapping = append(s.Operator.ExtraClusterPurposeMapping, purposeMapping...)
-
 		// apply openmcp operator manifests
-		if _, err := resources.CreateObjectsFromTemplateFile(ctx, c, tmpl, s.Operator); err != nil {
+		if _, err := resources.CreateObjectsFromTemplateFile(ctx, c, tmpl, data); err != nil {
 			return ctx, err
 		}
 		// wait for deployment to be ready
-		if err := wait.For(conditions.New(c.Client().Resources()).
-			DeploymentAvailable(s.Operator.Name, s.Operator.Namespace), s.Operator.WaitOpts...); err != nil {
+		if err := s.timings.component(PhaseOperator, "Operator", s.Operator.Name, func() error {
+			return wait.For(conditions.New(c.Client().Resources()).
+				DeploymentAvailable(s.Operator.Name, s.Operator.Namespace), s.Operator.WaitOpts...)
+		}); err != nil {
 			return ctx, err
 		}
 		klog.Info("openmcp operator ready")
@@ -157,73 +381,24 @@ func (s *OpenMCPSetup) installOpenMCPOperator(tmpl string) types.EnvFunc {
 
 func (s *OpenMCPSetup) installClusterProviders() env.Func {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		installs := []func() error{}
 		for _, cp := range s.ClusterProviders {
-			if err := providers.InstallClusterProvider(ctx, c, cp); err != nil {
-				return ctx, err
-			}
+			installs = append(installs, func() error {
+				if err := s.timings.component(PhaseClusterProviders, "ClusterProvider", cp.Name, func() error {
+					return providers.InstallClusterProvider(ctx, c, cp)
+				}); err != nil {
+					return fmt.Errorf("install cluster provider %s failed: %w", cp.Name, err)
+				}
+				return nil
+			})
 		}
-		return ctx, nil
+		return ctx, internal.RunConcurrently(s.Concurrency, installs...)
 	}
 }
 
-func (s *OpenMCPSetup) managePlatformCluster(platformClusterName string) env.Func {
+func (s *OpenMCPSetup) registerExtensionSchemes() env.Func {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
-		if len(s.ClusterProviders) == 0 {
-			return ctx, fmt.Errorf("no cluster providers found")
-		}
-
-		// Use the first cluster provider for the platform cluster
-		// TODO: Consider adding explicit PlatformClusterProvider field to OpenMCPSetup
-		platformClusterClusterProvider := s.ClusterProviders[0]
-
-		// Currently only kind provider is supported for platform cluster management
-		if platformClusterClusterProvider.Name != "kind" {
-			klog.Warningf("platform cluster provider type '%s' is not 'kind', skipping platform cluster resource creation", platformClusterClusterProvider.Name)
-			return ctx, nil
-		}
-
-		klog.Info("create platform cluster resource...")
-
-		platformCluster := &unstructured.Unstructured{
-			Object: map[string]interface{}{
-				"apiVersion": "clusters.openmcp.cloud/v1alpha1",
-				"kind":       "Cluster",
-				"metadata": map[string]interface{}{
-					"name":      "platform",
-					"namespace": s.Namespace,
-					"annotations": map[string]string{
-						"kind.clusters.openmcp.cloud/name": platformClusterName,
-					},
-				},
-				"spec": map[string]interface{}{
-					"kubernetes": map[string]interface{}{},
-					"profile":    "kind",
-					"purposes": []interface{}{
-						clustersv1alpha1.PURPOSE_PLATFORM,
-					},
-					"tenancy": string(clustersv1alpha1.TENANCY_SHARED),
-				},
-			},
-		}
-
-		// Create the platform cluster object in Kubernetes
-		if createErr := c.Client().Resources().Create(ctx, platformCluster); createErr != nil {
-			return ctx, createErr
-		}
-
-		klog.Info("platform cluster resource created")
-		return ctx, nil
-	}
-}
-
-func (s *OpenMCPSetup) installExtensions() env.Func {
-	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
-		klog.Info("install extensions...")
 		for _, ext := range s.Extensions {
-			klog.Infof("install extension %s", ext.Name())
-			if installErr := ext.Install(ctx, c); installErr != nil {
-				return ctx, fmt.Errorf("install extension %s failed: %v", ext.Name(), installErr)
-			}
 			if schemeErr := ext.RegisterSchemes(ctx, c.Client().Resources().GetScheme()); schemeErr != nil {
 				return ctx, fmt.Errorf("install extension scheme %s failed: %v", ext.Name(), schemeErr)
 			}
@@ -234,47 +409,89 @@ func (s *OpenMCPSetup) installExtensions() env.Func {
 
 func (s *OpenMCPSetup) installPlatformServices() env.Func {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		installs := []func() error{}
 		for _, ps := range s.PlatformServices {
-			if err := platformservices.InstallPlatformService(ctx, c, ps); err != nil {
-				return ctx, err
-			}
+			installs = append(installs, func() error {
+				if err := s.timings.component(PhasePlatformServices, "PlatformService", ps.Name, func() error {
+					return platformservices.InstallPlatformService(ctx, c, ps)
+				}); err != nil {
+					return fmt.Errorf("install platform service %s failed: %w", ps.Name, err)
+				}
+				return nil
+			})
 		}
-		return ctx, nil
+		return ctx, internal.RunConcurrently(s.Concurrency, installs...)
 	}
 }
 
 func (s *OpenMCPSetup) installServiceProviders() env.Func {
 	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		installs := []func() error{}
 		for _, sp := range s.ServiceProviders {
-			if err := providers.InstallServiceProvider(ctx, c, sp); err != nil {
-				return ctx, err
-			}
+			installs = append(installs, func() error {
+				if err := s.timings.component(PhaseServiceProviders, "ServiceProvider", sp.Name, func() error {
+					return providers.InstallServiceProvider(ctx, c, sp)
+				}); err != nil {
+					return fmt.Errorf("install service provider %s failed: %w", sp.Name, err)
+				}
+				return nil
+			})
 		}
-		return ctx, nil
+		return ctx, internal.RunConcurrently(s.Concurrency, installs...)
 	}
 }
 
-func (s *OpenMCPSetup) loadImagesToCluster(platformCluster string) env.Func {
-	funcs := []env.Func{}
+// localImages returns the images of all components that have to be made available in the kind clusters
+func (s *OpenMCPSetup) localImages() []*string {
+	images := []*string{}
 	if s.Operator.LoadImageToCluster {
-		funcs = append(funcs, envfuncs.LoadDockerImageToCluster(platformCluster, s.Operator.Image))
+		images = append(images, &s.Operator.Image)
+	}
+	for i := range s.ClusterProviders {
+		if s.ClusterProviders[i].LoadImageToCluster {
+			images = append(images, &s.ClusterProviders[i].Image)
+		}
 	}
-	for _, cp := range s.ClusterProviders {
-		if cp.LoadImageToCluster {
-			funcs = append(funcs, envfuncs.LoadDockerImageToCluster(platformCluster, cp.Image))
+	for i := range s.ServiceProviders {
+		if s.ServiceProviders[i].LoadImageToCluster {
+			images = append(images, &s.ServiceProviders[i].Image)
 		}
 	}
-	for _, sp := range s.ServiceProviders {
-		if sp.LoadImageToCluster {
-			funcs = append(funcs, envfuncs.LoadDockerImageToCluster(platformCluster, sp.Image))
+	for i := range s.PlatformServices {
+		if s.PlatformServices[i].LoadImageToCluster {
+			images = append(images, &s.PlatformServices[i].Image)
 		}
 	}
-	for _, cp := range s.PlatformServices {
-		if cp.LoadImageToCluster {
-			funcs = append(funcs, envfuncs.LoadDockerImageToCluster(platformCluster, cp.Image))
+	return images
+}
+
+// loadImagesToCluster loads the local images into the platform cluster or, if the registry is enabled,
+// pushes them to the registry
+func (s *OpenMCPSetup) loadImagesToCluster(platformCluster string, reg *registry) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if s.Registry.Enabled {
+			return ctx, reg.push(ctx, s.Concurrency, s.localImages())
+		}
+		if s.Offline.Enabled {
+			// all images have been imported into the nodes already
+			return ctx, nil
+		}
+		images := []string{}
+		for _, image := range s.localImages() {
+			images = append(images, *image)
+		}
+		loads := []func() error{}
+		for _, image := range slices.Compact(slices.Sorted(slices.Values(images))) {
+			loads = append(loads, func() error {
+				klog.Infof("load image %s", image)
+				if _, err := envfuncs.LoadDockerImageToCluster(platformCluster, image)(ctx, c); err != nil {
+					return fmt.Errorf("load image %s failed: %w", image, err)
+				}
+				return nil
+			})
 		}
+		return ctx, internal.RunConcurrently(s.Concurrency, loads...)
 	}
-	return Compose(funcs...)
 }
 
 // Compose executes multiple env.Funcs in a row
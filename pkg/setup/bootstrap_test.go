@@ -0,0 +1,72 @@
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+type uninstalledExtension struct {
+	plainExtension
+	name        string
+	dependsOn   []string
+	uninstalled *[]string
+}
+
+func (e *uninstalledExtension) Name() string { return e.name }
+
+func (e *uninstalledExtension) DependsOn() []string { return e.dependsOn }
+
+func (e *uninstalledExtension) Uninstall(context.Context, *envconf.Config) error {
+	*e.uninstalled = append(*e.uninstalled, e.name)
+	return nil
+}
+
+func TestUninstallExtensions(t *testing.T) {
+	uninstalled := []string{}
+	flux := &uninstalledExtension{name: "flux", uninstalled: &uninstalled}
+	app := &uninstalledExtension{name: "app", dependsOn: []string{"flux"}, uninstalled: &uninstalled}
+	s := &OpenMCPSetup{
+		Extensions: []extensions.Extension{
+			app,
+			&plainExtension{},
+			flux,
+			&uninstalledExtension{name: "not-installed", uninstalled: &uninstalled},
+		},
+		installed: &installedExtensions{},
+	}
+	platform := extensions.Target{Role: extensions.ClusterRolePlatform}
+	s.installed.add(flux, platform)
+	s.installed.add(&plainExtension{}, platform)
+	s.installed.add(app, platform)
+	s.installed.add(app, platform)
+	s.installed.add(app, extensions.Target{Role: extensions.ClusterRoleMCP})
+	_, err := s.uninstallExtensions()(context.Background(), envconf.New())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "flux"}, uninstalled)
+}
+
+type targetedExtension struct {
+	plainExtension
+	extensions.Placement
+}
+
+func TestInstallExtensionsDefersClusterTargets(t *testing.T) {
+	s := &OpenMCPSetup{
+		Extensions: []extensions.Extension{
+			&targetedExtension{Placement: extensions.Placement{Targets: []extensions.Target{
+				{Role: extensions.ClusterRoleWorkload},
+				{Role: extensions.ClusterRoleMCP, MCP: "test"},
+			}}},
+		},
+		installed: &installedExtensions{},
+	}
+	_, err := s.installExtensions()(context.Background(), envconf.New())
+	require.NoError(t, err)
+	assert.Empty(t, s.installed.all())
+}
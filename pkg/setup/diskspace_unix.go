@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package setup
+
+import "syscall"
+
+// freeDiskSpace returns the number of bytes available to unprivileged users on the file system of the path
+func freeDiskSpace(path string) (int64, error) {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
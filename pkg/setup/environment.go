@@ -0,0 +1,192 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+type environmentContextKey struct{}
+
+// OpenMCPEnvironment is a handle to an openMCP environment created by Bootstrap. Its cluster
+// accessors can be used once the setup phase of the test environment has run.
+// Within features, the handle can be retrieved with EnvironmentFromContext.
+type OpenMCPEnvironment struct {
+	// RunID identifies the test run, see OpenMCPSetup.RunID
+	RunID string
+	// PlatformClusterName is the name of the platform kind cluster
+	PlatformClusterName string
+	// Namespace is the namespace of the openMCP installation on the platform cluster
+	Namespace string
+
+	setup        *OpenMCPSetup
+	registry     *registry
+	platform     *envconf.Config
+	kubeconfigMu sync.Mutex
+	kubeconfigs  string
+	teardownMu   sync.Mutex
+	teardownErrs []error
+}
+
+// Component describes an installed openMCP component
+type Component struct {
+	// Kind is the kind of component, e.g. ServiceProvider
+	Kind string
+	// Name is the name of the component
+	Name string
+	// Image is the effective image of the component
+	Image string
+}
+
+// Version returns the tag of the component image
+func (c Component) Version() string {
+	image := c.Image
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// MCP describes a managed control plane of the environment
+type MCP struct {
+	Name      string
+	Namespace string
+	// Config is the config of the MCP cluster or nil if the MCP cluster is not available yet
+	Config *envconf.Config
+}
+
+// EnvironmentFromContext returns the environment handle that has been stored in the context during Bootstrap
+func EnvironmentFromContext(ctx context.Context) (*OpenMCPEnvironment, bool) {
+	e, ok := ctx.Value(environmentContextKey{}).(*OpenMCPEnvironment)
+	return e, ok
+}
+
+// bind makes the environment handle available in the context and binds it to the platform cluster config
+func (e *OpenMCPEnvironment) bind() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		e.platform = c
+		return context.WithValue(ctx, environmentContextKey{}, e), nil
+	}
+}
+
+// PlatformConfig returns the config of the platform cluster
+func (e *OpenMCPEnvironment) PlatformConfig() *envconf.Config {
+	return e.platform
+}
+
+// OnboardingConfig returns the config of the onboarding cluster with the default namespace
+func (e *OpenMCPEnvironment) OnboardingConfig() (*envconf.Config, error) {
+	return clusterutils.OnboardingConfig()
+}
+
+// WorkloadConfig returns the config of the workload cluster with the default namespace
+func (e *OpenMCPEnvironment) WorkloadConfig() (*envconf.Config, error) {
+	return clusterutils.WorkloadConfig()
+}
+
+// MCPConfig returns the config of the cluster of the MCP with the passed in name
+func (e *OpenMCPEnvironment) MCPConfig(ctx context.Context, name string) (*envconf.Config, error) {
+	return clusterutils.MCPConfig(ctx, e.platform, name)
+}
+
+// MCPs returns all MCPs of the onboarding cluster together with their cluster configs
+func (e *OpenMCPEnvironment) MCPs(ctx context.Context) ([]MCP, error) {
+	onboarding, err := e.OnboardingConfig()
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "core.open-control-plane.io",
+		Version: "v2alpha1",
+		Kind:    "ControlPlane",
+	})
+	if err := onboarding.Client().Resources().List(ctx, list); err != nil {
+		return nil, err
+	}
+	mcps := make([]MCP, 0, len(list.Items))
+	for _, item := range list.Items {
+		mcp := MCP{Name: item.GetName(), Namespace: item.GetNamespace()}
+		if cfg, err := e.MCPConfig(ctx, mcp.Name); err == nil {
+			mcp.Config = cfg
+		}
+		mcps = append(mcps, mcp)
+	}
+	return mcps, nil
+}
+
+// Components returns the installed openMCP components with their effective images
+func (e *OpenMCPEnvironment) Components() []Component {
+	s := e.setup
+	components := []Component{{Kind: "Operator", Name: s.Operator.Name, Image: s.Operator.Image}}
+	for _, cp := range s.ClusterProviders {
+		components = append(components, Component{Kind: "ClusterProvider", Name: cp.Name, Image: cp.Image})
+	}
+	for _, ps := range s.PlatformServices {
+		components = append(components, Component{Kind: "PlatformService", Name: ps.Name, Image: ps.Image})
+	}
+	for _, sp := range s.ServiceProviders {
+		components = append(components, Component{Kind: "ServiceProvider", Name: sp.Name, Image: sp.Image})
+	}
+	return components
+}
+
+// KubeconfigPaths returns the paths of kubeconfig files for the platform cluster and every other
+// kind cluster, keyed by cluster name. The kubeconfigs of the other clusters are written to a
+// temporary directory.
+func (e *OpenMCPEnvironment) KubeconfigPaths() (map[string]string, error) {
+	e.kubeconfigMu.Lock()
+	defer e.kubeconfigMu.Unlock()
+	if e.kubeconfigs == "" {
+		dir, err := os.MkdirTemp("", "openmcp-kubeconfigs-*")
+		if err != nil {
+			return nil, err
+		}
+		e.kubeconfigs = dir
+	}
+	paths := map[string]string{}
+	if e.platform != nil {
+		paths[e.PlatformClusterName] = e.platform.KubeconfigFile()
+	}
+	clusters, err := clusterutils.ClusterNames()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range clusters {
+		if _, ok := paths[name]; ok {
+			continue
+		}
+		kubeconfig, err := clusterutils.KubeConfig(name)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", name, err)
+		}
+		path := filepath.Join(e.kubeconfigs, name)
+		if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+			return nil, err
+		}
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+func (e *OpenMCPEnvironment) cleanup() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if e.kubeconfigs != "" {
+			os.RemoveAll(e.kubeconfigs)
+		}
+		return ctx, nil
+	}
+}
@@ -0,0 +1,231 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+// installExtensions installs the extensions in dependency order into their platform and onboarding clusters.
+// Workload and MCP clusters are created on demand, extensions that target them are installed by
+// InstallWorkloadExtensions and InstallMCPExtensions once the cluster has been requested.
+func (s *OpenMCPSetup) installExtensions() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		klog.Info("install extensions...")
+		sorted, err := extensions.Sort(s.Extensions)
+		if err != nil {
+			return ctx, err
+		}
+		for _, ext := range sorted {
+			for _, target := range extensions.TargetsOf(ext) {
+				if deferred(target) {
+					continue
+				}
+				if err := s.installExtension(ctx, c, ext, target); err != nil {
+					return ctx, err
+				}
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// deferred returns true if the cluster of the target doesn't exist during Bootstrap
+func deferred(target extensions.Target) bool {
+	return target.Role == extensions.ClusterRoleWorkload || target.Role == extensions.ClusterRoleMCP
+}
+
+// installExtension installs an extension into the cluster of the target, waits until it is ready
+// and registers its schemes with the client of the cluster
+func (s *OpenMCPSetup) installExtension(ctx context.Context, platform *envconf.Config, ext extensions.Extension, target extensions.Target) error {
+	cfg, err := targetConfig(ctx, platform, target, s.WaitOpts...)
+	if err != nil {
+		return fmt.Errorf("install extension %s failed: %s cluster not found: %w", ext.Name(), target, err)
+	}
+	klog.Infof("install extension %s into %s cluster", ext.Name(), target)
+	s.installed.add(ext, target)
+	if installErr := s.timings.component(PhaseExtensions, "Extension", extensionName(ext, target), func() error {
+		if err := ext.Install(ctx, cfg); err != nil {
+			return err
+		}
+		if waiter, ok := ext.(extensions.Waiter); ok {
+			return waiter.WaitReady(ctx, cfg)
+		}
+		return nil
+	}); installErr != nil {
+		return fmt.Errorf("install extension %s failed: %v", extensionName(ext, target), installErr)
+	}
+	if schemeErr := ext.RegisterSchemes(ctx, cfg.Client().Resources().GetScheme()); schemeErr != nil {
+		return fmt.Errorf("install extension scheme %s failed: %v", ext.Name(), schemeErr)
+	}
+	return nil
+}
+
+// uninstallExtensions uninstalls the extensions that implement extensions.Uninstaller from the clusters they
+// have been installed into, in reverse install order. Extensions are not uninstalled from MCP clusters.
+func (s *OpenMCPSetup) uninstallExtensions() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		errs := []error{}
+		for _, installed := range slices.Backward(s.installed.all()) {
+			uninstaller, ok := installed.ext.(extensions.Uninstaller)
+			if !ok || installed.target.Role == extensions.ClusterRoleMCP {
+				continue
+			}
+			klog.Infof("uninstall extension %s from %s cluster", installed.ext.Name(), installed.target)
+			cfg, err := resolveTarget(ctx, c, installed.target)
+			if err == nil {
+				err = uninstaller.Uninstall(ctx, cfg)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("uninstall extension %s failed: %w", extensionName(installed.ext, installed.target), err))
+			}
+		}
+		return ctx, errors.Join(errs...)
+	}
+}
+
+// installedExtension is an extension that has been installed into the cluster of the target
+type installedExtension struct {
+	ext    extensions.Extension
+	target extensions.Target
+}
+
+// installedExtensions records the clusters extensions have been installed into in install order
+type installedExtensions struct {
+	mu   sync.Mutex
+	list []installedExtension
+}
+
+// add records an installation, installations that have already been recorded are ignored
+func (i *installedExtensions) add(ext extensions.Extension, target extensions.Target) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	installed := installedExtension{ext: ext, target: target}
+	if !slices.Contains(i.list, installed) {
+		i.list = append(i.list, installed)
+	}
+}
+
+// all returns the recorded installations in install order
+func (i *installedExtensions) all() []installedExtension {
+	if i == nil {
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return slices.Clone(i.list)
+}
+
+// InstallWorkloadExtensions installs the extensions that target the workload cluster into the workload cluster
+// in dependency order. It waits until the workload cluster is available.
+func (e *OpenMCPEnvironment) InstallWorkloadExtensions(ctx context.Context) error {
+	return e.installDeferredExtensions(ctx, extensions.Target{Role: extensions.ClusterRoleWorkload},
+		func(t extensions.Target) bool { return t.Role == extensions.ClusterRoleWorkload })
+}
+
+// InstallWorkloadExtensions returns a feature step that installs the extensions that target the workload cluster
+func InstallWorkloadExtensions() features.Func {
+	return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		e, ok := EnvironmentFromContext(ctx)
+		if !ok {
+			t.Fatal("no openMCP environment found in context")
+		}
+		if err := e.InstallWorkloadExtensions(ctx); err != nil {
+			t.Errorf("install extensions into workload cluster failed: %v", err)
+		}
+		return ctx
+	}
+}
+
+// InstallMCPExtensions installs the extensions that target the MCP with the passed in name into the MCP cluster
+// in dependency order. It waits until the MCP cluster is available.
+func (e *OpenMCPEnvironment) InstallMCPExtensions(ctx context.Context, mcpName string) error {
+	return e.installDeferredExtensions(ctx, extensions.Target{Role: extensions.ClusterRoleMCP, MCP: mcpName},
+		func(t extensions.Target) bool { return t.Matches(mcpName) })
+}
+
+// InstallMCPExtensions returns a feature step that installs the extensions that target the MCP with the passed in name
+func InstallMCPExtensions(mcpName string) features.Func {
+	return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		e, ok := EnvironmentFromContext(ctx)
+		if !ok {
+			t.Fatal("no openMCP environment found in context")
+		}
+		if err := e.InstallMCPExtensions(ctx, mcpName); err != nil {
+			t.Errorf("install extensions into mcp %s failed: %v", mcpName, err)
+		}
+		return ctx
+	}
+}
+
+// installDeferredExtensions installs the extensions with a target that matches into the cluster of the passed in target
+func (e *OpenMCPEnvironment) installDeferredExtensions(ctx context.Context, target extensions.Target, matches func(extensions.Target) bool) error {
+	sorted, err := extensions.Sort(e.setup.Extensions)
+	if err != nil {
+		return err
+	}
+	for _, ext := range sorted {
+		if !slices.ContainsFunc(extensions.TargetsOf(ext), matches) {
+			continue
+		}
+		if err := e.setup.installExtension(ctx, e.platform, ext, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// targetConfig waits until the cluster of the target is available and returns its config
+func targetConfig(ctx context.Context, platform *envconf.Config, target extensions.Target, opts ...wait.Option) (*envconf.Config, error) {
+	if target.Role == extensions.ClusterRolePlatform {
+		return platform, nil
+	}
+	var cfg *envconf.Config
+	err := wait.For(func(ctx context.Context) (bool, error) {
+		var err error
+		if cfg, err = resolveTarget(ctx, platform, target); err != nil {
+			klog.V(2).Infof("%s cluster not available yet: %v", target, err)
+			return false, nil
+		}
+		return true, nil
+	}, opts...)
+	return cfg, err
+}
+
+// resolveTarget returns the config of the cluster of the target
+func resolveTarget(ctx context.Context, platform *envconf.Config, target extensions.Target) (*envconf.Config, error) {
+	switch target.Role {
+	case extensions.ClusterRolePlatform:
+		return platform, nil
+	case extensions.ClusterRoleOnboarding:
+		return clusterutils.OnboardingConfig()
+	case extensions.ClusterRoleWorkload:
+		return clusterutils.WorkloadConfig()
+	case extensions.ClusterRoleMCP:
+		return clusterutils.MCPConfig(ctx, platform, target.MCP)
+	}
+	return nil, fmt.Errorf("unknown target cluster role %q", target.Role)
+}
+
+// extensionName returns the name of the extension and, for clusters other than the platform cluster, the target
+func extensionName(ext extensions.Extension, target extensions.Target) string {
+	if target.Role == extensions.ClusterRolePlatform {
+		return ext.Name()
+	}
+	return ext.Name() + "@" + target.String()
+}
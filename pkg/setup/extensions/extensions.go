@@ -2,6 +2,8 @@ package extensions
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
@@ -10,6 +12,8 @@ import (
 // Extension represents a pluggable component that can be installed into the OpenMCP testing environment.
 // Extensions allow adding third-party tools, controllers, or services (like FluxCD, ArgoCD, etc.)
 // to the test environment in a modular and reusable way.
+// Extensions can implement the optional interfaces Waiter, Uninstaller, Dependent,
+// Renderer and PathResolver.
 //
 // Example usage:
 //
@@ -58,3 +62,84 @@ type Extension interface {
 	// Returns an error if scheme registration fails.
 	RegisterSchemes(context.Context, *runtime.Scheme) error
 }
+
+// Renderer is an optional interface of extensions that can render the manifests they install without
+// a cluster. It is used by the dry-run of the setup to write the manifests of the extension.
+type Renderer interface {
+	// Render returns the manifests Install applies as multi-document YAML
+	Render(context.Context) (string, error)
+}
+
+// Waiter is an optional interface of extensions that become ready some time after Install returned,
+// e.g. because controllers have to start. WaitReady is called after Install and blocks until the
+// extension is ready.
+type Waiter interface {
+	WaitReady(context.Context, *envconf.Config) error
+}
+
+// Uninstaller is an optional interface of extensions that remove their resources during the teardown.
+// Extensions are uninstalled in reverse install order.
+type Uninstaller interface {
+	Uninstall(context.Context, *envconf.Config) error
+}
+
+// Dependent is an optional interface of extensions that have to be installed after other extensions
+type Dependent interface {
+	// DependsOn returns the names of the extensions that have to be installed first
+	DependsOn() []string
+}
+
+// PathResolver is an optional interface of extensions that reference local files. Extensions created from a
+// setup file resolve their relative paths against the directory of the setup file.
+type PathResolver interface {
+	// ResolvePaths makes the relative paths of the extension relative to baseDir
+	ResolvePaths(baseDir string)
+}
+
+// Sort returns the extensions in install order, i.e. every extension after the extensions it depends on.
+// Extensions without dependencies between them keep their order. It fails for duplicate names, unknown
+// dependencies and dependency cycles.
+func Sort(exts []Extension) ([]Extension, error) {
+	byName := map[string]Extension{}
+	for _, ext := range exts {
+		if _, ok := byName[ext.Name()]; ok {
+			return nil, fmt.Errorf("duplicate extension %s", ext.Name())
+		}
+		byName[ext.Name()] = ext
+	}
+	sorted := make([]Extension, 0, len(exts))
+	// state is 1 while the dependencies of an extension are visited and 2 once it has been sorted
+	state := map[string]int{}
+	var visit func(ext Extension, path []string) error
+	visit = func(ext Extension, path []string) error {
+		name := ext.Name()
+		path = append(path, name)
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("extension dependency cycle: %s", strings.Join(path, " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		if dependent, ok := ext.(Dependent); ok {
+			for _, dep := range dependent.DependsOn() {
+				depExt, ok := byName[dep]
+				if !ok {
+					return fmt.Errorf("extension %s depends on unknown extension %s", name, dep)
+				}
+				if err := visit(depExt, path); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = 2
+		sorted = append(sorted, ext)
+		return nil
+	}
+	for _, ext := range exts {
+		if err := visit(ext, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,81 @@
+package extensions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+type fakeExtension struct {
+	name      string
+	dependsOn []string
+}
+
+func (e *fakeExtension) Name() string { return e.name }
+
+func (e *fakeExtension) Install(context.Context, *envconf.Config) error { return nil }
+
+func (e *fakeExtension) RegisterSchemes(context.Context, *runtime.Scheme) error { return nil }
+
+func (e *fakeExtension) DependsOn() []string { return e.dependsOn }
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		exts    []Extension
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "no dependencies keep their order",
+			exts: []Extension{&fakeExtension{name: "b"}, &fakeExtension{name: "a"}},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "dependencies are installed first",
+			exts: []Extension{
+				&fakeExtension{name: "app", dependsOn: []string{"flux", "certs"}},
+				&fakeExtension{name: "flux", dependsOn: []string{"certs"}},
+				&fakeExtension{name: "certs"},
+				&fakeExtension{name: "other"},
+			},
+			want: []string{"certs", "flux", "app", "other"},
+		},
+		{
+			name:    "unknown dependency",
+			exts:    []Extension{&fakeExtension{name: "app", dependsOn: []string{"flux"}}},
+			wantErr: "extension app depends on unknown extension flux",
+		},
+		{
+			name: "cycle",
+			exts: []Extension{
+				&fakeExtension{name: "a", dependsOn: []string{"b"}},
+				&fakeExtension{name: "b", dependsOn: []string{"a"}},
+			},
+			wantErr: "extension dependency cycle: a -> b -> a",
+		},
+		{
+			name:    "duplicate",
+			exts:    []Extension{&fakeExtension{name: "a"}, &fakeExtension{name: "a"}},
+			wantErr: "duplicate extension a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := Sort(tt.exts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			names := []string{}
+			for _, ext := range sorted {
+				names = append(names, ext.Name())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -3,11 +3,16 @@ package fluxcd
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
 	"sigs.k8s.io/e2e-framework/pkg/envconf"
 	"sigs.k8s.io/yaml"
 
@@ -15,37 +20,95 @@ import (
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	kustomize1 "github.com/fluxcd/kustomize-controller/api/v1"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
 )
 
 // FluxCD is an Extension that installs FluxCD (https://fluxcd.io) into the test environment.
 // FluxCD is a GitOps continuous delivery solution for Kubernetes that keeps clusters in sync
 // with configuration sources (like Git repositories) and automates configuration updates.
 //
-// This extension installs the following FluxCD components:
+// By default, this extension installs the following FluxCD components:
 //   - source-controller: Handles source definitions (Git, Helm, OCI repositories)
 //   - kustomize-controller: Applies Kustomize overlays from sources
 //   - helm-controller: Manages Helm releases
 //   - notification-controller: Handles event notifications and webhooks
-//   - image-reflector-controller: Scans container registries for image metadata
-//   - image-automation-controller: Automates image updates in Git
+//
+// The image automation components image-reflector-controller and image-automation-controller and the
+// source-watcher can be selected with Components.
 //
 // All components are installed into the specified namespace (defaults to "flux-system").
+// Install blocks until the Deployments of all components are available.
 //
 // Usage:
 //
 //	openmcp := setup.OpenMCPSetup{
 //	    Extensions: []extensions.Extension{
 //	        &fluxcd.FluxCD{
-//	            Namespace: "custom-flux-ns", // Optional, defaults to "flux-system"
+//	            Namespace:  "custom-flux-ns", // Optional, defaults to "flux-system"
+//	            Version:    "v2.9.3",         // Optional, defaults to the version of the flux2 module
+//	            Components: []string{"source-controller", "helm-controller"},
+//	            Images: map[string]string{ // Optional, e.g. to test a locally built controller
+//	                "helm-controller": "localhost:5000/helm-controller:dev",
+//	            },
+//	            Placement: extensions.Placement{ // Optional, defaults to the platform cluster
+//	                Targets: []extensions.Target{{Role: extensions.ClusterRoleMCP, MCP: "test"}},
+//	            },
 //	        },
 //	    },
 //	}
 type FluxCD struct {
 	// Namespace is the Kubernetes namespace where FluxCD components will be installed.
 	// If empty, defaults to "flux-system".
-	Namespace string
+	Namespace string `json:"namespace,omitempty"`
+	// Components are the FluxCD components to install, defaults to the four core controllers
+	Components []string `json:"components,omitempty"`
+	// Version is the FluxCD release whose manifests are installed, e.g. v2.9.3 or latest.
+	// Defaults to the version of the flux2 module this package is built with.
+	Version string `json:"version,omitempty"`
+	// Registry is the container registry the controller images are pulled from, defaults to ghcr.io/fluxcd
+	Registry string `json:"registry,omitempty"`
+	// NetworkPolicy installs the network policies of FluxCD, defaults to true
+	NetworkPolicy *bool `json:"networkPolicy,omitempty"`
+	// LogLevel is the log level of the controllers (debug, info or error), defaults to info
+	LogLevel string `json:"logLevel,omitempty"`
+	// WatchAllNamespaces makes the controllers watch all namespaces instead of their own, defaults to true
+	WatchAllNamespaces *bool `json:"watchAllNamespaces,omitempty"`
+	// Images override the images of components by component name
+	Images map[string]string `json:"images,omitempty"`
+	// Timeout is the time the controllers have to become available, defaults to 5m
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Placement configures the clusters FluxCD is installed into, by default the platform cluster
+	extensions.Placement `json:",inline"`
+
+	// manifests are the manifests applied by Install
+	manifests string
 }
 
+const (
+	// defaultVersion is the version of the flux2 module, its manifests match the registered API types
+	defaultVersion = "v2.9.3"
+	// defaultTimeout is the default time the FluxCD controllers have to become available
+	defaultTimeout = 5 * time.Minute
+)
+
+// defaultComponents are the FluxCD controllers that are installed by default, knownComponents are all
+// components that can be installed
+var (
+	defaultComponents = []string{
+		"source-controller",
+		"kustomize-controller",
+		"helm-controller",
+		"notification-controller",
+	}
+	knownComponents = slices.Concat(defaultComponents, []string{
+		"image-reflector-controller",
+		"image-automation-controller",
+		"source-watcher",
+	})
+)
+
 // Name returns the unique identifier for this extension.
 func (f *FluxCD) Name() string {
 	return "fluxcd"
@@ -56,63 +119,177 @@ func (f *FluxCD) Name() string {
 // eliminating the need for the external flux CLI tool.
 //
 // The installation process:
-//  1. Generates installation manifests using flux2's manifestgen package
-//  2. Splits the multi-document YAML into individual resources
-//  3. Unmarshals each resource as an unstructured object
-//  4. Creates each resource in the cluster (gracefully handling already-exists errors)
+//  1. Generates installation manifests, see Render
+//  2. Creates the CustomResourceDefinitions and waits until they are established
+//  3. Creates the remaining resources (gracefully handling already-exists errors)
+//  4. Waits until the rendered Deployments of the components are available
 //
-// Returns an error if manifest generation or resource creation fails.
+// Returns an error if manifest generation, resource creation or the readiness wait fails.
 func (f *FluxCD) Install(ctx context.Context, cfg *envconf.Config) error {
-	klog.Info("installing flux...")
+	klog.Infof("installing flux %s...", f.version())
 
-	// Use configured namespace or default to "flux-system"
-	namespace := f.Namespace
-	if namespace == "" {
-		namespace = "flux-system"
+	content, err := f.Render(ctx)
+	if err != nil {
+		return err
+	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return fmt.Errorf("failed to decode flux manifests: %w", err)
+	}
+	f.manifests = content
+	if err := resources.CreateObjects(ctx, cfg, f.namespace(), objs, f.waitOpts(ctx)...); err != nil {
+		return err
 	}
+	for _, name := range deployments(objs) {
+		if err := wait.For(conditions.New(cfg.Client().Resources()).DeploymentAvailable(name, f.namespace()),
+			f.waitOpts(ctx)...); err != nil {
+			return fmt.Errorf("flux controller %s not available: %w", name, err)
+		}
+	}
+
+	klog.Infof("flux installed successfully in namespace %s", f.namespace())
+	return nil
+}
+
+// deployments returns the names of the rendered controller Deployments, only these are waited for
+func deployments(objs []*unstructured.Unstructured) []string {
+	names := []string{}
+	for _, obj := range objs {
+		if obj.GetKind() == "Deployment" {
+			names = append(names, obj.GetName())
+		}
+	}
+	return names
+}
 
-	// Generate the Flux installation manifests
+// Render generates the FluxCD installation manifests using flux2's manifestgen package.
+// The manifests of the flux release are downloaded, no cluster is required.
+func (f *FluxCD) Render(_ context.Context) (string, error) {
+	for _, name := range f.components() {
+		if !slices.Contains(knownComponents, name) {
+			return "", fmt.Errorf("unknown flux component %s, known components: %v", name, knownComponents)
+		}
+	}
+	manifest, err := fluxinstall.Generate(f.options(), "")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate flux manifests: %w", err)
+	}
+	if len(f.Images) == 0 {
+		return manifest.Content, nil
+	}
+	return f.overrideImages(manifest.Content)
+}
+
+// options returns the manifestgen options of the configuration. All components are passed as Components
+// because manifestgen doesn't render ComponentsExtra.
+func (f *FluxCD) options() fluxinstall.Options {
 	options := fluxinstall.MakeDefaultOptions()
-	options.Namespace = namespace
-	options.Components = []string{
-		"source-controller",
-		"kustomize-controller",
-		"helm-controller",
-		"notification-controller",
+	options.Namespace = f.namespace()
+	options.Version = f.version()
+	options.Components = f.components()
+	options.ComponentsExtra = nil
+	if f.Registry != "" {
+		options.Registry = f.Registry
 	}
-	options.ComponentsExtra = []string{
-		"image-reflector-controller",
-		"image-automation-controller",
+	if f.NetworkPolicy != nil {
+		options.NetworkPolicy = *f.NetworkPolicy
 	}
+	if f.LogLevel != "" {
+		options.LogLevel = f.LogLevel
+	}
+	if f.WatchAllNamespaces != nil {
+		options.WatchAllNamespaces = *f.WatchAllNamespaces
+	}
+	return options
+}
 
-	manifest, err := fluxinstall.Generate(options, "")
+// overrideImages replaces the image of the manager container of the component Deployments
+func (f *FluxCD) overrideImages(content string) (string, error) {
+	for name := range f.Images {
+		if !slices.Contains(f.components(), name) {
+			return "", fmt.Errorf("image override for flux component %s which is not installed", name)
+		}
+	}
+	objs, err := resources.DecodeManifest(content)
 	if err != nil {
-		return fmt.Errorf("failed to generate flux manifests: %w", err)
+		return "", fmt.Errorf("failed to decode flux manifests: %w", err)
 	}
-
-	// Split and apply the manifests
-	manifests := strings.Split(manifest.Content, "---\n")
-	for _, m := range manifests {
-		if strings.TrimSpace(m) == "" {
-			continue
+	docs := []string{}
+	for _, obj := range objs {
+		if image, ok := f.Images[obj.GetName()]; ok && obj.GetKind() == "Deployment" {
+			if err := setManagerImage(obj, image); err != nil {
+				return "", fmt.Errorf("failed to override image of flux component %s: %w", obj.GetName(), err)
+			}
+		}
+		doc, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
 		}
+		docs = append(docs, string(doc))
+	}
+	return "---\n" + strings.Join(docs, "---\n"), nil
+}
 
-		obj := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal([]byte(m), obj); err != nil {
-			return fmt.Errorf("failed to unmarshal manifest: %w", err)
+func setManagerImage(obj *unstructured.Unstructured, image string) error {
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if container, ok := c.(map[string]interface{}); ok && container["name"] == "manager" {
+			container["image"] = image
+			return unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")
 		}
+	}
+	return fmt.Errorf("container manager not found")
+}
 
-		if err := cfg.Client().Resources().Create(ctx, obj); err != nil {
-			// Ignore already exists errors
-			if !strings.Contains(err.Error(), "already exists") {
-				return fmt.Errorf("failed to create resource %s/%s: %w",
-					obj.GetKind(), obj.GetName(), err)
-			}
+// Uninstall deletes the resources created by Install in reverse order without waiting for their deletion
+func (f *FluxCD) Uninstall(ctx context.Context, cfg *envconf.Config) error {
+	content := f.manifests
+	if content == "" {
+		var err error
+		if content, err = f.Render(ctx); err != nil {
+			return err
 		}
 	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return err
+	}
+	return resources.DeleteObjects(ctx, cfg, objs)
+}
 
-	klog.Infof("flux installed successfully in namespace %s", namespace)
-	return nil
+// components returns the configured components or defaults to the core controllers
+func (f *FluxCD) components() []string {
+	if len(f.Components) == 0 {
+		return defaultComponents
+	}
+	return f.Components
+}
+
+// version returns the configured version or defaults to the version of the flux2 module
+func (f *FluxCD) version() string {
+	if f.Version == "" {
+		return defaultVersion
+	}
+	return f.Version
+}
+
+func (f *FluxCD) waitOpts(ctx context.Context) []wait.Option {
+	timeout := defaultTimeout
+	if f.Timeout != nil {
+		timeout = f.Timeout.Duration
+	}
+	return []wait.Option{wait.WithContext(ctx), wait.WithTimeout(timeout)}
+}
+
+// namespace returns the configured namespace or defaults to "flux-system"
+func (f *FluxCD) namespace() string {
+	if f.Namespace == "" {
+		return "flux-system"
+	}
+	return f.Namespace
 }
 
 // RegisterSchemes registers FluxCD's custom resource schemes with the Kubernetes client.
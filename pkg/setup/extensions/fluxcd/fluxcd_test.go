@@ -0,0 +1,140 @@
+package fluxcd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+)
+
+func TestOptions(t *testing.T) {
+	options := (&FluxCD{}).options()
+	assert.Equal(t, "flux-system", options.Namespace)
+	assert.Equal(t, defaultVersion, options.Version)
+	assert.Equal(t, defaultComponents, options.Components)
+	assert.Empty(t, options.ComponentsExtra)
+	assert.True(t, options.NetworkPolicy)
+	assert.True(t, options.WatchAllNamespaces)
+
+	disabled := false
+	options = (&FluxCD{
+		Namespace:          "flux",
+		Version:            "v2.8.0",
+		Components:         []string{"source-controller", "image-reflector-controller"},
+		Registry:           "localhost:5000/fluxcd",
+		NetworkPolicy:      &disabled,
+		LogLevel:           "debug",
+		WatchAllNamespaces: &disabled,
+	}).options()
+	assert.Equal(t, "flux", options.Namespace)
+	assert.Equal(t, "v2.8.0", options.Version)
+	assert.Equal(t, []string{"source-controller", "image-reflector-controller"}, options.Components)
+	assert.Equal(t, "localhost:5000/fluxcd", options.Registry)
+	assert.False(t, options.NetworkPolicy)
+	assert.Equal(t, "debug", options.LogLevel)
+	assert.False(t, options.WatchAllNamespaces)
+}
+
+func TestRenderUnknownComponent(t *testing.T) {
+	_, err := (&FluxCD{Components: []string{"source-controller", "argo"}}).Render(context.Background())
+	assert.ErrorContains(t, err, "unknown flux component argo")
+}
+
+func TestOverrideImages(t *testing.T) {
+	content := `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: flux-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: source-controller
+  namespace: flux-system
+spec:
+  template:
+    spec:
+      containers:
+        - name: manager
+          image: ghcr.io/fluxcd/source-controller:v1.7.0
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: helm-controller
+  namespace: flux-system
+spec:
+  template:
+    spec:
+      containers:
+        - name: manager
+          image: ghcr.io/fluxcd/helm-controller:v1.4.0
+`
+	tests := []struct {
+		name       string
+		images     map[string]string
+		wantImages []string
+		wantErr    string
+	}{
+		{
+			name:       "overrides the image of the component",
+			images:     map[string]string{"helm-controller": "localhost:5000/helm-controller:dev"},
+			wantImages: []string{"ghcr.io/fluxcd/source-controller:v1.7.0", "localhost:5000/helm-controller:dev"},
+		},
+		{
+			name:    "component not installed",
+			images:  map[string]string{"image-automation-controller": "localhost:5000/iac:dev"},
+			wantErr: "image override for flux component image-automation-controller which is not installed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overridden, err := (&FluxCD{Images: tt.images}).overrideImages(content)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			objs, err := resources.DecodeManifest(overridden)
+			require.NoError(t, err)
+			require.Len(t, objs, 3)
+			images := []string{}
+			for _, obj := range objs[1:] {
+				containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				images = append(images, containers[0].(map[string]interface{})["image"].(string))
+			}
+			assert.Equal(t, tt.wantImages, images)
+		})
+	}
+}
+
+func TestDeployments(t *testing.T) {
+	objs, err := resources.DecodeManifest(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: flux-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: source-controller
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: source-controller
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: helm-controller
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"source-controller", "helm-controller"}, deployments(objs))
+}
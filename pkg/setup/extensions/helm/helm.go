@@ -0,0 +1,348 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+// Helm is an Extension that installs a Helm chart from a local directory or .tgz archive into the test
+// environment. The chart is rendered in-process like `helm template` does, no helm binary is required.
+// The rendered objects are created directly, i.e. no Helm release is stored in the cluster.
+//
+// Pre-install hooks are created before the objects of the chart, post-install hooks after them. Other
+// hooks are not rendered.
+//
+// Usage:
+//
+//	openmcp := setup.OpenMCPSetup{
+//	    Extensions: []extensions.Extension{
+//	        &helm.Helm{
+//	            Release:     "podinfo",
+//	            Chart:       "testdata/podinfo-6.7.0.tgz",
+//	            Namespace:   "podinfo",
+//	            ValuesFiles: []string{"testdata/podinfo-values.yaml"},
+//	            Values:      map[string]interface{}{"replicaCount": 2},
+//	        },
+//	    },
+//	}
+type Helm struct {
+	// Release is the name of the release, it is also the name of the extension
+	Release string `json:"release"`
+	// Chart is the path of the chart directory or .tgz archive
+	Chart string `json:"chart"`
+	// Namespace is the namespace of the release. It is created if it doesn't exist and defaults to "default".
+	Namespace string `json:"namespace,omitempty"`
+	// ValuesFiles are values files that are merged in order, later files override earlier ones
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+	// Values are merged over the values files
+	Values map[string]interface{} `json:"values,omitempty"`
+	// Timeout is the time the workloads of the release have to become ready, defaults to 5m
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Dependencies are the names of the extensions that have to be installed before the release
+	Dependencies []string `json:"dependsOn,omitempty"`
+	// Placement configures the clusters the release is installed into, by default the platform cluster
+	extensions.Placement `json:",inline"`
+
+	// installations are the installations of the release by cluster, see extensions.ClusterKey
+	installations map[string]*installation
+	mu            sync.Mutex
+}
+
+// installation is the state of the release in one cluster
+type installation struct {
+	// manifests are the manifests applied by Install
+	manifests string
+	// createdNamespace is true if Install created the namespace of the release
+	createdNamespace bool
+}
+
+// defaultTimeout is the default time the workloads of the release have to become ready
+const defaultTimeout = 5 * time.Minute
+
+// serverVersion, createNamespace, deleteNamespace, createObjects and deleteObjects access the cluster
+var (
+	serverVersion   = discoverServerVersion
+	createNamespace = createNamespaceIfMissing
+	deleteNamespace = deleteNamespaceIfExists
+	createObjects   = resources.CreateObjects
+	deleteObjects   = resources.DeleteObjects
+)
+
+// Name returns the name of the release
+func (h *Helm) Name() string {
+	return h.Release
+}
+
+// DependsOn returns the extensions that have to be installed before the release
+func (h *Helm) DependsOn() []string {
+	return h.Dependencies
+}
+
+// ResolvePaths makes the chart and values file paths relative to baseDir
+func (h *Helm) ResolvePaths(baseDir string) {
+	h.Chart = resolvePath(baseDir, h.Chart)
+	for i, file := range h.ValuesFiles {
+		h.ValuesFiles[i] = resolvePath(baseDir, file)
+	}
+}
+
+func resolvePath(baseDir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// Install renders the chart for the Kubernetes version of the cluster, creates the namespace of the release
+// and the rendered objects. CustomResourceDefinitions are created first.
+func (h *Helm) Install(ctx context.Context, cfg *envconf.Config) error {
+	klog.Infof("installing helm release %s from %s...", h.Release, h.Chart)
+	inst := h.installation(cfg)
+	kubeVersion, err := serverVersion(cfg)
+	if err != nil {
+		return err
+	}
+	content, err := h.render(kubeVersion)
+	if err != nil {
+		return err
+	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return fmt.Errorf("failed to decode manifests of release %s: %w", h.Release, err)
+	}
+	created, err := createNamespace(ctx, cfg, h.namespace())
+	if err != nil {
+		return err
+	}
+	if created {
+		inst.createdNamespace = true
+	}
+	inst.manifests = content
+	if err := createObjects(ctx, cfg, h.namespace(), objs, h.waitOpts(ctx)...); err != nil {
+		return fmt.Errorf("failed to install release %s: %w", h.Release, err)
+	}
+	klog.Infof("helm release %s installed in namespace %s", h.Release, h.namespace())
+	return nil
+}
+
+// installation returns the state of the release in the cluster of the config
+func (h *Helm) installation(cfg *envconf.Config) *installation {
+	key := extensions.ClusterKey(cfg)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.installations == nil {
+		h.installations = map[string]*installation{}
+	}
+	inst, ok := h.installations[key]
+	if !ok {
+		inst = &installation{}
+		h.installations[key] = inst
+	}
+	return inst
+}
+
+// createNamespaceIfMissing creates the namespace and returns true if it didn't exist before
+func createNamespaceIfMissing(ctx context.Context, cfg *envconf.Config, name string) (bool, error) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	err := cfg.Client().Resources().Create(ctx, ns)
+	if apierrors.IsAlreadyExists(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create namespace %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// deleteNamespaceIfExists deletes the namespace without waiting for its deletion
+func deleteNamespaceIfExists(ctx context.Context, cfg *envconf.Config, name string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := cfg.Client().Resources().Delete(ctx, ns); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// discoverServerVersion returns the Kubernetes version of the cluster
+func discoverServerVersion(cfg *envconf.Config) (*chartutil.KubeVersion, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(cfg.Client().RESTConfig())
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover server version: %w", err)
+	}
+	return chartutil.ParseKubeVersion(info.GitVersion)
+}
+
+// Render renders the chart for Helm's default Kubernetes version
+func (h *Helm) Render(_ context.Context) (string, error) {
+	return h.render(nil)
+}
+
+// render renders the pre-install hooks, the objects and the post-install hooks of the chart.
+// If kubeVersion is nil, Helm's default version is used.
+func (h *Helm) render(kubeVersion *chartutil.KubeVersion) (string, error) {
+	chart, err := loader.Load(h.Chart)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart %s: %w", h.Chart, err)
+	}
+	values, err := h.values()
+	if err != nil {
+		return "", err
+	}
+	install := action.NewInstall(&action.Configuration{Log: klog.V(4).Infof})
+	install.ReleaseName = h.Release
+	install.Namespace = h.namespace()
+	install.DryRun = true
+	install.ClientOnly = true
+	install.IncludeCRDs = true
+	install.Replace = true
+	install.KubeVersion = kubeVersion
+	rel, err := install.Run(chart, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to render chart %s: %w", h.Chart, err)
+	}
+	manifests := slices.Concat(hooks(rel.Hooks, release.HookPreInstall), []string{rel.Manifest},
+		hooks(rel.Hooks, release.HookPostInstall))
+	return strings.Join(manifests, "\n---\n"), nil
+}
+
+// hooks returns the manifests of the hooks of the event ordered by weight and name like Helm runs them
+func hooks(all []*release.Hook, event release.HookEvent) []string {
+	matching := slices.DeleteFunc(slices.Clone(all), func(hook *release.Hook) bool {
+		return !slices.Contains(hook.Events, event)
+	})
+	slices.SortStableFunc(matching, func(a, b *release.Hook) int {
+		if a.Weight != b.Weight {
+			return a.Weight - b.Weight
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	manifests := []string{}
+	for _, hook := range matching {
+		manifests = append(manifests, hook.Manifest)
+	}
+	return manifests
+}
+
+// values merges the values files in order and the inline values over them
+func (h *Helm) values() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, file := range h.ValuesFiles {
+		fileValues, err := chartutil.ReadValuesFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %w", file, err)
+		}
+		values = mergeValues(values, fileValues)
+	}
+	return mergeValues(values, h.Values), nil
+}
+
+// mergeValues merges override into base recursively, values of override take precedence
+func mergeValues(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		if overrideMap, ok := v.(map[string]interface{}); ok {
+			if baseMap, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = mergeValues(baseMap, overrideMap)
+				continue
+			}
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// WaitReady waits until the Deployments, StatefulSets and DaemonSets of the release are ready
+func (h *Helm) WaitReady(ctx context.Context, cfg *envconf.Config) error {
+	objs, err := resources.DecodeManifest(h.installation(cfg).manifests)
+	if err != nil {
+		return err
+	}
+	if err := resources.WaitForWorkloads(ctx, cfg, h.namespaced(objs), h.waitOpts(ctx)...); err != nil {
+		return fmt.Errorf("helm release %s not ready: %w", h.Release, err)
+	}
+	klog.Infof("helm release %s ready", h.Release)
+	return nil
+}
+
+// Uninstall deletes the objects of the release in reverse order without waiting for their deletion.
+// The namespace is deleted if Install created it in the cluster.
+func (h *Helm) Uninstall(ctx context.Context, cfg *envconf.Config) error {
+	inst := h.installation(cfg)
+	content := inst.manifests
+	if content == "" {
+		var err error
+		if content, err = h.Render(ctx); err != nil {
+			return err
+		}
+	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return err
+	}
+	errs := []error{deleteObjects(ctx, cfg, h.namespaced(objs))}
+	if inst.createdNamespace {
+		errs = append(errs, deleteNamespace(ctx, cfg, h.namespace()))
+	}
+	return errors.Join(errs...)
+}
+
+// namespaced sets the namespace of the release on objects without namespace
+func (h *Helm) namespaced(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(h.namespace())
+		}
+	}
+	return objs
+}
+
+// RegisterSchemes does nothing, the objects of the release are created as unstructured objects
+func (h *Helm) RegisterSchemes(_ context.Context, _ *runtime.Scheme) error {
+	return nil
+}
+
+// namespace returns the configured namespace or defaults to "default"
+func (h *Helm) namespace() string {
+	if h.Namespace == "" {
+		return "default"
+	}
+	return h.Namespace
+}
+
+func (h *Helm) waitOpts(ctx context.Context) []wait.Option {
+	timeout := defaultTimeout
+	if h.Timeout != nil {
+		timeout = h.Timeout.Duration
+	}
+	return []wait.Option{wait.WithContext(ctx), wait.WithTimeout(timeout)}
+}
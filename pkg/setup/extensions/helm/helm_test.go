@@ -0,0 +1,130 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name         string
+		helm         *Helm
+		wantReplicas int64
+		wantImage    string
+	}{
+		{
+			name:         "chart defaults",
+			helm:         &Helm{Release: "app", Chart: "testdata/chart"},
+			wantReplicas: 1,
+			wantImage:    "nginx:latest",
+		},
+		{
+			name:         "values file",
+			helm:         &Helm{Release: "app", Chart: "testdata/chart", ValuesFiles: []string{"testdata/values.yaml"}},
+			wantReplicas: 2,
+			wantImage:    "nginx:1.27",
+		},
+		{
+			name: "inline values override values files",
+			helm: &Helm{
+				Release:     "app",
+				Chart:       "testdata/chart",
+				ValuesFiles: []string{"testdata/values.yaml"},
+				Values:      map[string]interface{}{"image": map[string]interface{}{"repository": "busybox"}},
+			},
+			wantReplicas: 2,
+			wantImage:    "busybox:1.27",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := tt.helm.Render(context.Background())
+			require.NoError(t, err)
+			objs, err := resources.DecodeManifest(content)
+			require.NoError(t, err)
+			names := []string{}
+			for _, obj := range objs {
+				names = append(names, obj.GetKind()+"/"+obj.GetName())
+			}
+			assert.Equal(t, []string{"ConfigMap/app-pre", "CustomResourceDefinition/tests.example.com",
+				"Deployment/app", "ConfigMap/app-post"}, names)
+			deployment := objs[2]
+			assert.Equal(t, "default", deployment.GetNamespace())
+			replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+			assert.Equal(t, tt.wantReplicas, replicas)
+			containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+			require.Len(t, containers, 1)
+			assert.Equal(t, tt.wantImage, containers[0].(map[string]interface{})["image"])
+		})
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	h := &Helm{Chart: "chart", ValuesFiles: []string{"values.yaml", "/abs/values.yaml"}}
+	h.ResolvePaths("/setup")
+	assert.Equal(t, "/setup/chart", h.Chart)
+	assert.Equal(t, []string{"/setup/values.yaml", "/abs/values.yaml"}, h.ValuesFiles)
+}
+
+func TestInstallIntoSeveralClusters(t *testing.T) {
+	const newCluster, existingCluster = "https://new:6443", "https://existing:6443"
+	kubeVersions := map[string]string{newCluster: "v1.34.1", existingCluster: "v1.33.4"}
+	createdObjects := map[string]int{}
+	deletedObjects := map[string]string{}
+	deletedNamespaces := []string{}
+	defer func() {
+		serverVersion, createNamespace, deleteNamespace = discoverServerVersion, createNamespaceIfMissing, deleteNamespaceIfExists
+		createObjects, deleteObjects = resources.CreateObjects, resources.DeleteObjects
+	}()
+	serverVersion = func(cfg *envconf.Config) (*chartutil.KubeVersion, error) {
+		return chartutil.ParseKubeVersion(kubeVersions[cfg.Client().RESTConfig().Host])
+	}
+	createNamespace = func(_ context.Context, cfg *envconf.Config, _ string) (bool, error) {
+		return cfg.Client().RESTConfig().Host != existingCluster, nil
+	}
+	deleteNamespace = func(_ context.Context, cfg *envconf.Config, name string) error {
+		deletedNamespaces = append(deletedNamespaces, cfg.Client().RESTConfig().Host+"/"+name)
+		return nil
+	}
+	createObjects = func(_ context.Context, cfg *envconf.Config, _ string, objs []*unstructured.Unstructured, _ ...wait.Option) error {
+		createdObjects[cfg.Client().RESTConfig().Host] = len(objs)
+		return nil
+	}
+	deleteObjects = func(_ context.Context, cfg *envconf.Config, objs []*unstructured.Unstructured) error {
+		for _, obj := range objs {
+			if obj.GetName() == "app-pre" {
+				deletedObjects[cfg.Client().RESTConfig().Host], _, _ = unstructured.NestedString(obj.Object, "data", "kubeVersion")
+			}
+		}
+		return nil
+	}
+
+	h := &Helm{Release: "app", Chart: "testdata/chart", Namespace: "app"}
+	clusters := []*envconf.Config{clusterConfig(t, newCluster), clusterConfig(t, existingCluster)}
+	for _, cfg := range clusters {
+		require.NoError(t, h.Install(context.Background(), cfg))
+	}
+	for _, cfg := range clusters {
+		require.NoError(t, h.Uninstall(context.Background(), cfg))
+	}
+	assert.Equal(t, map[string]int{newCluster: 4, existingCluster: 4}, createdObjects)
+	assert.Equal(t, map[string]string{newCluster: "v1.34.1", existingCluster: "v1.33.4"}, deletedObjects)
+	assert.Equal(t, []string{newCluster + "/app"}, deletedNamespaces)
+}
+
+func clusterConfig(t *testing.T, host string) *envconf.Config {
+	client, err := klient.New(&rest.Config{Host: host})
+	require.NoError(t, err)
+	return envconf.New().WithClient(client)
+}
@@ -0,0 +1,297 @@
+package manifests
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+// Manifests is an Extension that applies plain YAML manifests or a kustomization from a directory or an
+// fs.FS like an embed.FS. Kustomizations are built in-process, no kustomize or kubectl binary is required.
+//
+// Without Kustomize, all .yaml, .yml and .json files below the directory are applied in lexical order of
+// their paths. CustomResourceDefinitions and Namespaces are created first. Install waits until the
+// CustomResourceDefinitions are established and, in WaitReady, until the Deployments are available.
+//
+// Usage:
+//
+//	//go:embed testdata/prerequisites
+//	var prerequisites embed.FS
+//
+//	openmcp := setup.OpenMCPSetup{
+//	    Extensions: []extensions.Extension{
+//	        &manifests.Manifests{
+//	            ID:        "prerequisites",
+//	            FS:        prerequisites,
+//	            Dir:       "testdata/prerequisites/overlays/test",
+//	            Kustomize: true,
+//	        },
+//	    },
+//	}
+type Manifests struct {
+	// ID is the name of the extension
+	ID string `json:"name"`
+	// Dir is the directory of the manifests or the kustomization. If FS is set, Dir is a path within FS
+	// and defaults to its root.
+	Dir string `json:"dir"`
+	// FS is an optional file system the manifests are read from, e.g. an embed.FS
+	FS fs.FS `json:"-"`
+	// Kustomize builds the kustomization in Dir instead of reading the manifests
+	Kustomize bool `json:"kustomize,omitempty"`
+	// Namespace is the namespace of namespaced objects without namespace, defaults to "default"
+	Namespace string `json:"namespace,omitempty"`
+	// Timeout is the time the CustomResourceDefinitions and Deployments have to become ready, defaults to 5m
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Dependencies are the names of the extensions that have to be installed before the manifests
+	Dependencies []string `json:"dependsOn,omitempty"`
+	// Placement configures the clusters the manifests are applied to, by default the platform cluster
+	extensions.Placement `json:",inline"`
+
+	// manifests are the manifests applied by Install by cluster, see extensions.ClusterKey
+	manifests map[string]string
+	mu        sync.Mutex
+}
+
+// defaultTimeout is the default time the CustomResourceDefinitions and Deployments have to become ready
+const defaultTimeout = 5 * time.Minute
+
+// createObjects and deleteObjects access the cluster
+var (
+	createObjects = resources.CreateObjects
+	deleteObjects = resources.DeleteObjects
+)
+
+// Name returns the configured ID
+func (m *Manifests) Name() string {
+	return m.ID
+}
+
+// DependsOn returns the extensions that have to be installed before the manifests
+func (m *Manifests) DependsOn() []string {
+	return m.Dependencies
+}
+
+// ResolvePaths makes the directory relative to baseDir unless the manifests are read from FS
+func (m *Manifests) ResolvePaths(baseDir string) {
+	if m.FS != nil || m.Dir == "" || filepath.IsAbs(m.Dir) {
+		return
+	}
+	m.Dir = filepath.Join(baseDir, m.Dir)
+}
+
+// Install creates the objects, CustomResourceDefinitions and Namespaces first
+func (m *Manifests) Install(ctx context.Context, cfg *envconf.Config) error {
+	klog.Infof("applying manifests %s from %s...", m.ID, m.source())
+	content, err := m.Render(ctx)
+	if err != nil {
+		return err
+	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return fmt.Errorf("failed to decode manifests %s: %w", m.ID, err)
+	}
+	m.setApplied(cfg, content)
+	if err := createObjects(ctx, cfg, m.namespace(), objs, m.waitOpts(ctx)...); err != nil {
+		return fmt.Errorf("failed to apply manifests %s: %w", m.ID, err)
+	}
+	klog.Infof("manifests %s applied", m.ID)
+	return nil
+}
+
+// Render returns the manifests read from the directory or the output of the kustomize build
+func (m *Manifests) Render(_ context.Context) (string, error) {
+	if m.Kustomize {
+		return m.build()
+	}
+	return m.read()
+}
+
+// read concatenates the manifest files below the directory in lexical order
+func (m *Manifests) read() (string, error) {
+	fsys, root := m.fileSystem()
+	docs := []string{}
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isManifest(p) {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(data))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifests from %s: %w", m.source(), err)
+	}
+	return strings.Join(docs, "\n---\n"), nil
+}
+
+func isManifest(p string) bool {
+	switch path.Ext(p) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// build runs the kustomize build of the directory. Kustomizations on disk can reference bases outside of
+// the directory, kustomizations in FS are copied into memory first.
+func (m *Manifests) build() (string, error) {
+	kfs, dir := filesys.MakeFsOnDisk(), cmp.Or(m.Dir, ".")
+	if m.FS != nil {
+		var err error
+		if kfs, err = inMemory(m.FS); err != nil {
+			return "", fmt.Errorf("failed to read kustomization from %s: %w", m.source(), err)
+		}
+		_, root := m.fileSystem()
+		dir = "/" + root
+	}
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(kfs, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to build kustomization %s: %w", m.source(), err)
+	}
+	content, err := resMap.AsYaml()
+	if err != nil {
+		return "", fmt.Errorf("failed to build kustomization %s: %w", m.source(), err)
+	}
+	return string(content), nil
+}
+
+// inMemory copies the file system into an in-memory kustomize file system
+func inMemory(fsys fs.FS) (filesys.FileSystem, error) {
+	kfs := filesys.MakeFsInMemory()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return kfs.MkdirAll("/" + p)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return kfs.WriteFile("/"+p, data)
+	})
+	return kfs, err
+}
+
+// fileSystem returns the file system and the root of the manifests within it
+func (m *Manifests) fileSystem() (fs.FS, string) {
+	if m.FS != nil {
+		return m.FS, path.Clean(strings.TrimPrefix(m.Dir, "/"))
+	}
+	return os.DirFS(cmp.Or(m.Dir, ".")), "."
+}
+
+// source describes where the manifests are read from for logs and errors
+func (m *Manifests) source() string {
+	if m.FS != nil {
+		return "embedded directory " + m.Dir
+	}
+	return m.Dir
+}
+
+// WaitReady waits until the Deployments, StatefulSets and DaemonSets of the manifests are ready
+func (m *Manifests) WaitReady(ctx context.Context, cfg *envconf.Config) error {
+	objs, err := m.objects(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if err := resources.WaitForWorkloads(ctx, cfg, objs, m.waitOpts(ctx)...); err != nil {
+		return fmt.Errorf("manifests %s not ready: %w", m.ID, err)
+	}
+	klog.Infof("manifests %s ready", m.ID)
+	return nil
+}
+
+// Uninstall deletes the objects in reverse order without waiting for their deletion
+func (m *Manifests) Uninstall(ctx context.Context, cfg *envconf.Config) error {
+	objs, err := m.objects(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return deleteObjects(ctx, cfg, objs)
+}
+
+// setApplied records the manifests applied to the cluster of the config
+func (m *Manifests) setApplied(cfg *envconf.Config, content string) {
+	key := extensions.ClusterKey(cfg)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.manifests == nil {
+		m.manifests = map[string]string{}
+	}
+	m.manifests[key] = content
+}
+
+// applied returns the manifests applied to the cluster of the config
+func (m *Manifests) applied(cfg *envconf.Config) string {
+	key := extensions.ClusterKey(cfg)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.manifests[key]
+}
+
+// objects returns the objects applied to the cluster by Install or renders them again. Namespaced objects
+// without namespace get the configured namespace.
+func (m *Manifests) objects(ctx context.Context, cfg *envconf.Config) ([]*unstructured.Unstructured, error) {
+	content := m.applied(cfg)
+	if content == "" {
+		var err error
+		if content, err = m.Render(ctx); err != nil {
+			return nil, err
+		}
+	}
+	objs, err := resources.DecodeManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(m.namespace())
+		}
+	}
+	return objs, nil
+}
+
+// RegisterSchemes does nothing, the objects are created as unstructured objects
+func (m *Manifests) RegisterSchemes(_ context.Context, _ *runtime.Scheme) error {
+	return nil
+}
+
+// namespace returns the configured namespace or defaults to "default"
+func (m *Manifests) namespace() string {
+	if m.Namespace == "" {
+		return "default"
+	}
+	return m.Namespace
+}
+
+func (m *Manifests) waitOpts(ctx context.Context) []wait.Option {
+	timeout := defaultTimeout
+	if m.Timeout != nil {
+		timeout = m.Timeout.Duration
+	}
+	return []wait.Option{wait.WithContext(ctx), wait.WithTimeout(timeout)}
+}
@@ -0,0 +1,116 @@
+package manifests
+
+import (
+	"context"
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/e2e-framework/klient"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+)
+
+//go:embed testdata
+var testdata embed.FS
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifests *Manifests
+		want      []string
+	}{
+		{
+			name:      "directory",
+			manifests: &Manifests{ID: "plain", Dir: "testdata/plain"},
+			want:      []string{"CustomResourceDefinition//tests.example.com", "Deployment//app", "ConfigMap/other/config"},
+		},
+		{
+			name:      "embedded directory",
+			manifests: &Manifests{ID: "plain", FS: testdata, Dir: "testdata/plain"},
+			want:      []string{"CustomResourceDefinition//tests.example.com", "Deployment//app", "ConfigMap/other/config"},
+		},
+		{
+			name:      "kustomization",
+			manifests: &Manifests{ID: "overlay", Dir: "testdata/kustomize/overlay", Kustomize: true},
+			want:      []string{"Deployment/overlay/test-app"},
+		},
+		{
+			name:      "embedded kustomization",
+			manifests: &Manifests{ID: "overlay", FS: testdata, Dir: "testdata/kustomize/overlay", Kustomize: true},
+			want:      []string{"Deployment/overlay/test-app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := tt.manifests.Render(context.Background())
+			require.NoError(t, err)
+			objs, err := resources.DecodeManifest(content)
+			require.NoError(t, err)
+			names := []string{}
+			for _, obj := range objs {
+				names = append(names, obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	m := &Manifests{Dir: "manifests"}
+	m.ResolvePaths("/setup")
+	assert.Equal(t, "/setup/manifests", m.Dir)
+
+	m = &Manifests{FS: testdata, Dir: "testdata/plain"}
+	m.ResolvePaths("/setup")
+	assert.Equal(t, "testdata/plain", m.Dir)
+}
+
+func TestUninstallFromSeveralClusters(t *testing.T) {
+	deleted := map[string][]string{}
+	createObjects = func(context.Context, *envconf.Config, string, []*unstructured.Unstructured, ...wait.Option) error {
+		return nil
+	}
+	deleteObjects = func(_ context.Context, cfg *envconf.Config, objs []*unstructured.Unstructured) error {
+		host := cfg.Client().RESTConfig().Host
+		for _, obj := range objs {
+			deleted[host] = append(deleted[host], obj.GetNamespace()+"/"+obj.GetName())
+		}
+		return nil
+	}
+	defer func() {
+		createObjects, deleteObjects = resources.CreateObjects, resources.DeleteObjects
+	}()
+
+	dir := t.TempDir()
+	writeConfigMap := func(name string) {
+		content := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(content), 0o644))
+	}
+	m := &Manifests{ID: "config", Dir: dir}
+	first, second := clusterConfig(t, "https://first:6443"), clusterConfig(t, "https://second:6443")
+	writeConfigMap("v1")
+	require.NoError(t, m.Install(context.Background(), first))
+	writeConfigMap("v2")
+	require.NoError(t, m.Install(context.Background(), second))
+	writeConfigMap("v3")
+	require.NoError(t, m.Uninstall(context.Background(), first))
+	require.NoError(t, m.Uninstall(context.Background(), second))
+	assert.Equal(t, map[string][]string{
+		"https://first:6443":  {"default/v1"},
+		"https://second:6443": {"default/v2"},
+	}, deleted)
+}
+
+func clusterConfig(t *testing.T, host string) *envconf.Config {
+	client, err := klient.New(&rest.Config{Host: host})
+	require.NoError(t, err)
+	return envconf.New().WithClient(client)
+}
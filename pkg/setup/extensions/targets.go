@@ -0,0 +1,87 @@
+package extensions
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// ClusterRole is the role of a cluster of the environment
+type ClusterRole string
+
+const (
+	ClusterRolePlatform   ClusterRole = "platform"
+	ClusterRoleOnboarding ClusterRole = "onboarding"
+	ClusterRoleWorkload   ClusterRole = "workload"
+	ClusterRoleMCP        ClusterRole = "mcp"
+)
+
+// Target identifies a cluster an extension is installed into
+type Target struct {
+	// Role is the role of the cluster
+	Role ClusterRole `json:"role"`
+	// MCP is the name of the MCP whose cluster is targeted if Role is mcp. If empty, every MCP is targeted.
+	MCP string `json:"mcp,omitempty"`
+}
+
+// String returns the role of the target and, for a specific MCP, its name, e.g. mcp/test
+func (t Target) String() string {
+	if t.Role == ClusterRoleMCP && t.MCP != "" {
+		return string(t.Role) + "/" + t.MCP
+	}
+	return string(t.Role)
+}
+
+// Validate checks that the role of the target is known and that only MCP targets name an MCP
+func (t Target) Validate() error {
+	switch t.Role {
+	case ClusterRolePlatform, ClusterRoleOnboarding, ClusterRoleWorkload:
+		if t.MCP != "" {
+			return fmt.Errorf("target %s: mcp can only be set for role %s", t.Role, ClusterRoleMCP)
+		}
+	case ClusterRoleMCP:
+	default:
+		return fmt.Errorf("unknown target cluster role %q", t.Role)
+	}
+	return nil
+}
+
+// Matches returns true if the target is the cluster of the MCP with the passed in name
+func (t Target) Matches(mcpName string) bool {
+	return t.Role == ClusterRoleMCP && (t.MCP == "" || t.MCP == mcpName)
+}
+
+// Targeted is an optional interface of extensions that are installed into other clusters than the platform
+// cluster or into several clusters
+type Targeted interface {
+	// InstallTargets returns the clusters the extension is installed into
+	InstallTargets() []Target
+}
+
+// Placement can be embedded into extensions to make their target clusters configurable
+type Placement struct {
+	// Targets are the clusters the extension is installed into. If empty, the extension is installed
+	// into the platform cluster.
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// InstallTargets returns the configured targets
+func (p Placement) InstallTargets() []Target {
+	return p.Targets
+}
+
+// TargetsOf returns the clusters the extension is installed into, by default the platform cluster
+func TargetsOf(ext Extension) []Target {
+	if targeted, ok := ext.(Targeted); ok {
+		if targets := targeted.InstallTargets(); len(targets) > 0 {
+			return targets
+		}
+	}
+	return []Target{{Role: ClusterRolePlatform}}
+}
+
+// ClusterKey identifies the cluster of the config by its API server. Extensions that are installed into
+// several clusters use it to keep the state of each installation apart.
+func ClusterKey(cfg *envconf.Config) string {
+	return cfg.Client().RESTConfig().Host
+}
@@ -0,0 +1,48 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type targetedExtension struct {
+	fakeExtension
+	Placement
+}
+
+func TestTargetsOf(t *testing.T) {
+	platform := []Target{{Role: ClusterRolePlatform}}
+	assert.Equal(t, platform, TargetsOf(&fakeExtension{name: "plain"}))
+	assert.Equal(t, platform, TargetsOf(&targetedExtension{}))
+	targets := []Target{{Role: ClusterRoleOnboarding}, {Role: ClusterRoleMCP, MCP: "test"}}
+	assert.Equal(t, targets, TargetsOf(&targetedExtension{Placement: Placement{Targets: targets}}))
+}
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		target      Target
+		wantString  string
+		wantErr     string
+		matchesTest bool
+	}{
+		{target: Target{Role: ClusterRolePlatform}, wantString: "platform"},
+		{target: Target{Role: ClusterRoleWorkload}, wantString: "workload"},
+		{target: Target{Role: ClusterRoleMCP}, wantString: "mcp", matchesTest: true},
+		{target: Target{Role: ClusterRoleMCP, MCP: "test"}, wantString: "mcp/test", matchesTest: true},
+		{target: Target{Role: ClusterRoleMCP, MCP: "other"}, wantString: "mcp/other"},
+		{target: Target{Role: ClusterRoleOnboarding, MCP: "test"}, wantString: "onboarding", wantErr: "target onboarding: mcp can only be set for role mcp"},
+		{target: Target{Role: "unknown"}, wantString: "unknown", wantErr: `unknown target cluster role "unknown"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantString, func(t *testing.T) {
+			assert.Equal(t, tt.wantString, tt.target.String())
+			assert.Equal(t, tt.matchesTest, tt.target.Matches("test"))
+			if tt.wantErr == "" {
+				assert.NoError(t, tt.target.Validate())
+			} else {
+				assert.EqualError(t, tt.target.Validate(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package setup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	providerv1alpha1 "github.com/openmcp-project/openmcp-operator/api/provider/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/platformservices"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions/fluxcd"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions/helm"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions/manifests"
+)
+
+const (
+	// SetupFileAPIVersion is the apiVersion of a setup file
+	SetupFileAPIVersion = "testing.openmcp.cloud/v1alpha1"
+	// SetupFileKind is the kind of a setup file
+	SetupFileKind = "OpenMCPSetup"
+)
+
+// SetupFile is the declarative representation of an OpenMCPSetup that can be written as YAML or JSON.
+// Relative paths within the file are resolved relative to the directory of the file.
+//
+// Example:
+//
+//	apiVersion: testing.openmcp.cloud/v1alpha1
+//	kind: OpenMCPSetup
+//	namespace: openmcp-system
+//	timeout: 5m
+//	operator:
+//	  name: openmcp-operator
+//	  image: ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0
+//	  environment: debug
+//	  platformName: platform
+//	platformCluster:
+//	  nodeImage: kindest/node:v1.35.0
+//	platformClusterProvider: kind
+//	clusterProviders:
+//	  - name: kind
+//	    image: ghcr.io/openmcp-project/images/cluster-provider-kind:v0.4.1
+//	serviceProviders:
+//	  - name: crossplane
+//	    image: ghcr.io/openmcp-project/images/service-provider-crossplane:v1.0.0
+//	    timeout: 2m
+//	platformServices:
+//	  - name: gateway
+//	    image: ghcr.io/openmcp-project/images/platform-service-gateway:v0.0.10
+//	    configsDir: platformservice-gateway
+//	extensions:
+//	  - name: fluxcd
+//	    config:
+//	      namespace: flux-system
+type SetupFile struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is the namespace of the openMCP installation on the platform cluster
+	Namespace string `json:"namespace"`
+	// Timeout is the timeout of the environment wide readiness checks
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// RunID identifies the test run, see OpenMCPSetup.RunID
+	RunID string `json:"runId,omitempty"`
+	// Reuse enables the reuse mode, see OpenMCPSetup.Reuse
+	Reuse bool `json:"reuse,omitempty"`
+	// Concurrency limits the number of concurrent image loads and installations, see OpenMCPSetup.Concurrency
+	Concurrency int `json:"concurrency,omitempty"`
+	// Registry configures the local OCI registry, see OpenMCPSetup.Registry
+	Registry RegistrySetup `json:"registry,omitempty"`
+	// Offline configures the pre-seeding of images, see OpenMCPSetup.Offline
+	Offline OfflineSetup `json:"offline,omitempty"`
+	// Preflight configures the preflight checks, see OpenMCPSetup.Preflight
+	Preflight PreflightSetup `json:"preflight,omitempty"`
+	// Teardown configures the teardown of the environment
+	Teardown TeardownSetup `json:"teardown,omitempty"`
+	// ArtifactsDir is the directory diagnostics are collected into on failure, see OpenMCPSetup.ArtifactsDir
+	ArtifactsDir string `json:"artifactsDir,omitempty"`
+	// ReportFile is the file the bootstrap report is written to, see OpenMCPSetup.ReportFile
+	ReportFile string `json:"reportFile,omitempty"`
+	// Operator configures the openmcp-operator
+	Operator OperatorFile `json:"operator"`
+	// PlatformCluster configures the topology of the platform kind cluster
+	PlatformCluster KindClusterSetup `json:"platformCluster,omitempty"`
+	// PlatformClusterProvider is the name of the cluster provider that manages the platform cluster
+	PlatformClusterProvider string `json:"platformClusterProvider,omitempty"`
+	// ClusterProviders are the cluster providers to install
+	ClusterProviders []ClusterProviderFile `json:"clusterProviders"`
+	// ServiceProviders are the service providers to install
+	ServiceProviders []ComponentFile `json:"serviceProviders,omitempty"`
+	// PlatformServices are the platform services to install
+	PlatformServices []PlatformServiceFile `json:"platformServices,omitempty"`
+	// Extensions are the extensions to install, see RegisterExtensionFactory
+	Extensions []ExtensionFile `json:"extensions,omitempty"`
+}
+
+// OperatorFile is the declarative representation of an OpenMCPOperatorSetup
+type OperatorFile struct {
+	Name                       string                            `json:"name"`
+	Image                      string                            `json:"image"`
+	Environment                string                            `json:"environment,omitempty"`
+	PlatformName               string                            `json:"platformName,omitempty"`
+	LoadImageToCluster         bool                              `json:"loadImageToCluster,omitempty"`
+	ExtraClusterPurposeMapping []providers.ClusterPurposeMapping `json:"extraClusterPurposeMapping,omitempty"`
+	// Config is merged into the default operator configuration, see OpenMCPOperatorSetup.Config
+	Config *OperatorConfig `json:"config,omitempty"`
+	// RawConfig is merged into the operator configuration, see OpenMCPOperatorSetup.RawConfig
+	RawConfig map[string]interface{} `json:"rawConfig,omitempty"`
+	// InitArgs, Args, Env, Resources, Replicas, ImagePullPolicy, ImagePullSecrets and Verbosity
+	// customize the operator Deployment, see OpenMCPOperatorSetup
+	InitArgs         []string                      `json:"initArgs,omitempty"`
+	Args             []string                      `json:"args,omitempty"`
+	Env              []corev1.EnvVar               `json:"env,omitempty"`
+	Resources        corev1.ResourceRequirements   `json:"resources,omitempty"`
+	Replicas         int32                         `json:"replicas,omitempty"`
+	ImagePullPolicy  corev1.PullPolicy             `json:"imagePullPolicy,omitempty"`
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	Verbosity        string                        `json:"verbosity,omitempty"`
+	// Timeout is the timeout to wait for the operator to become available
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ComponentFile is the declarative representation of an openMCP component like a service provider
+type ComponentFile struct {
+	Name               string `json:"name"`
+	Image              string `json:"image"`
+	LoadImageToCluster bool   `json:"loadImageToCluster,omitempty"`
+	// Timeout is the timeout to wait for the component to become ready or to be deleted
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ClusterProviderFile is the declarative representation of a ClusterProviderSetup
+type ClusterProviderFile struct {
+	ComponentFile  `json:",inline"`
+	DeploymentSpec *providerv1alpha1.DeploymentSpec `json:"deploymentSpec,omitempty"`
+}
+
+// PlatformServiceFile is the declarative representation of a PlatformServiceSetup
+type PlatformServiceFile struct {
+	ComponentFile `json:",inline"`
+	// ConfigsDir is an optional directory with platform service specific resources
+	ConfigsDir string `json:"configsDir,omitempty"`
+}
+
+// ExtensionFile references a registered extension by name and passes its configuration
+type ExtensionFile struct {
+	Name   string          `json:"name"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// ExtensionFactory creates an extension from its raw JSON configuration which can be empty
+type ExtensionFactory func(config []byte) (extensions.Extension, error)
+
+var extensionFactories = map[string]ExtensionFactory{
+	"fluxcd":    NewExtensionFactory[fluxcd.FluxCD](),
+	"helm":      NewExtensionFactory[helm.Helm](),
+	"manifests": NewExtensionFactory[manifests.Manifests](),
+}
+
+// RegisterExtensionFactory makes an extension available to setup files under the passed in name
+func RegisterExtensionFactory(name string, factory ExtensionFactory) {
+	extensionFactories[name] = factory
+}
+
+// NewExtensionFactory returns an ExtensionFactory that decodes the configuration into a new T.
+// Unknown configuration fields are rejected.
+func NewExtensionFactory[T any, PT interface {
+	*T
+	extensions.Extension
+}]() ExtensionFactory {
+	return func(config []byte) (extensions.Extension, error) {
+		ext := PT(new(T))
+		if len(config) > 0 {
+			if err := strictUnmarshalJSON(config, ext); err != nil {
+				return nil, err
+			}
+		}
+		return ext, nil
+	}
+}
+
+func strictUnmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// LoadOpenMCPSetup reads a setup file in YAML or JSON format, validates it and returns the resulting OpenMCPSetup
+func LoadOpenMCPSetup(path string) (*OpenMCPSetup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &SetupFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse setup file %s: %w", path, err)
+	}
+	s, err := file.toOpenMCPSetup(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid setup file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (f *SetupFile) toOpenMCPSetup(baseDir string) (*OpenMCPSetup, error) {
+	errs := f.validate()
+	s := &OpenMCPSetup{
+		Namespace: f.Namespace,
+		Operator: OpenMCPOperatorSetup{
+			Name:                       f.Operator.Name,
+			Image:                      f.Operator.Image,
+			Environment:                f.Operator.Environment,
+			PlatformName:               f.Operator.PlatformName,
+			WaitOpts:                   waitOpts(f.Operator.Timeout),
+			LoadImageToCluster:         f.Operator.LoadImageToCluster,
+			ExtraClusterPurposeMapping: f.Operator.ExtraClusterPurposeMapping,
+			Config:                     f.Operator.Config,
+			RawConfig:                  f.Operator.RawConfig,
+			InitArgs:                   f.Operator.InitArgs,
+			Args:                       f.Operator.Args,
+			Env:                        f.Operator.Env,
+			Resources:                  f.Operator.Resources,
+			Replicas:                   f.Operator.Replicas,
+			ImagePullPolicy:            f.Operator.ImagePullPolicy,
+			ImagePullSecrets:           f.Operator.ImagePullSecrets,
+			Verbosity:                  f.Operator.Verbosity,
+		},
+		PlatformCluster:         f.PlatformCluster,
+		PlatformClusterProvider: f.PlatformClusterProvider,
+		WaitOpts:                waitOpts(f.Timeout),
+		RunID:                   f.RunID,
+		Reuse:                   f.Reuse,
+		Concurrency:             f.Concurrency,
+		Registry:                f.Registry,
+		Offline:                 f.Offline,
+		Preflight:               f.Preflight,
+		Teardown:                f.Teardown,
+		ArtifactsDir:            f.ArtifactsDir,
+		ReportFile:              f.ReportFile,
+	}
+	s.PlatformCluster.ConfigFile = resolvePath(baseDir, f.PlatformCluster.ConfigFile)
+	s.Offline.ImageArchiveDir = resolvePath(baseDir, f.Offline.ImageArchiveDir)
+	s.Offline.ImageListFiles = nil
+	for _, file := range f.Offline.ImageListFiles {
+		s.Offline.ImageListFiles = append(s.Offline.ImageListFiles, resolvePath(baseDir, file))
+	}
+	for _, cp := range f.ClusterProviders {
+		s.ClusterProviders = append(s.ClusterProviders, providers.ClusterProviderSetup{
+			Name:               cp.Name,
+			Image:              cp.Image,
+			WaitOpts:           waitOpts(cp.Timeout),
+			LoadImageToCluster: cp.LoadImageToCluster,
+			DeploymentSpec:     cp.DeploymentSpec,
+		})
+	}
+	for _, sp := range f.ServiceProviders {
+		s.ServiceProviders = append(s.ServiceProviders, providers.ServiceProviderSetup{
+			Name:               sp.Name,
+			Image:              sp.Image,
+			WaitOpts:           waitOpts(sp.Timeout),
+			LoadImageToCluster: sp.LoadImageToCluster,
+		})
+	}
+	for _, ps := range f.PlatformServices {
+		s.PlatformServices = append(s.PlatformServices, platformservices.PlatformServiceSetup{
+			Name:                      ps.Name,
+			Image:                     ps.Image,
+			WaitOpts:                  waitOpts(ps.Timeout),
+			LoadImageToCluster:        ps.LoadImageToCluster,
+			PlatformServiceConfigsDir: resolvePath(baseDir, ps.ConfigsDir),
+		})
+	}
+	for _, e := range f.Extensions {
+		factory, ok := extensionFactories[e.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("extension %s is not registered, registered extensions: %v", e.Name, registeredExtensions()))
+			continue
+		}
+		ext, err := factory(e.Config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("extension %s: %w", e.Name, err))
+			continue
+		}
+		if resolver, ok := ext.(extensions.PathResolver); ok {
+			resolver.ResolvePaths(baseDir)
+		}
+		s.Extensions = append(s.Extensions, ext)
+	}
+	if len(s.ClusterProviders) > 0 {
+		if _, err := s.platformClusterProvider(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (f *SetupFile) validate() []error {
+	errs := []error{}
+	if f.APIVersion != SetupFileAPIVersion {
+		errs = append(errs, fmt.Errorf("apiVersion must be %s", SetupFileAPIVersion))
+	}
+	if f.Kind != SetupFileKind {
+		errs = append(errs, fmt.Errorf("kind must be %s", SetupFileKind))
+	}
+	if f.Namespace == "" {
+		errs = append(errs, errors.New("namespace must be set"))
+	}
+	errs = append(errs, validateComponent("operator", ComponentFile{Name: f.Operator.Name, Image: f.Operator.Image})...)
+	if len(f.ClusterProviders) == 0 {
+		errs = append(errs, errors.New("at least one cluster provider must be configured"))
+	}
+	names := map[string]bool{}
+	for i, cp := range f.ClusterProviders {
+		errs = append(errs, validateUniqueComponent(fmt.Sprintf("clusterProviders[%d]", i), cp.ComponentFile, names)...)
+	}
+	names = map[string]bool{}
+	for i, sp := range f.ServiceProviders {
+		errs = append(errs, validateUniqueComponent(fmt.Sprintf("serviceProviders[%d]", i), sp, names)...)
+	}
+	names = map[string]bool{}
+	for i, ps := range f.PlatformServices {
+		errs = append(errs, validateUniqueComponent(fmt.Sprintf("platformServices[%d]", i), ps.ComponentFile, names)...)
+	}
+	return errs
+}
+
+func validateUniqueComponent(field string, c ComponentFile, names map[string]bool) []error {
+	errs := validateComponent(field, c)
+	if names[c.Name] {
+		errs = append(errs, fmt.Errorf("%s: duplicate name %s", field, c.Name))
+	}
+	names[c.Name] = true
+	return errs
+}
+
+func validateComponent(field string, c ComponentFile) []error {
+	errs := []error{}
+	if c.Name == "" {
+		errs = append(errs, fmt.Errorf("%s: name must be set", field))
+	}
+	if c.Image == "" {
+		errs = append(errs, fmt.Errorf("%s: image must be set", field))
+	}
+	return errs
+}
+
+func waitOpts(timeout *metav1.Duration) []wait.Option {
+	if timeout == nil {
+		return nil
+	}
+	return []wait.Option{wait.WithTimeout(timeout.Duration)}
+}
+
+func resolvePath(baseDir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+func registeredExtensions() []string {
+	names := make([]string, 0, len(extensionFactories))
+	for name := range extensionFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
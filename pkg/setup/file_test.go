@@ -0,0 +1,60 @@
+package setup
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions/fluxcd"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions/helm"
+)
+
+func TestLoadOpenMCPSetup(t *testing.T) {
+	s, err := LoadOpenMCPSetup("testdata/setup.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "openmcp-system", s.Namespace)
+	assert.Len(t, s.WaitOpts, 1)
+	assert.Equal(t, "ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0", s.Operator.Image)
+	assert.Equal(t, "debug", s.Operator.Environment)
+	assert.Len(t, s.Operator.ExtraClusterPurposeMapping, 1)
+	assert.Empty(t, s.Operator.WaitOpts)
+	assert.Equal(t, filepath.Join("testdata", "kind-config.yaml"), s.PlatformCluster.ConfigFile)
+	assert.Equal(t, "kindest/node:v1.35.0", s.PlatformCluster.NodeImage)
+	require.Len(t, s.ClusterProviders, 1)
+	assert.True(t, s.ClusterProviders[0].LoadImageToCluster)
+	require.Len(t, s.ServiceProviders, 1)
+	assert.Len(t, s.ServiceProviders[0].WaitOpts, 1)
+	require.Len(t, s.PlatformServices, 1)
+	assert.Equal(t, filepath.Join("testdata", "platformservice-gateway"), s.PlatformServices[0].PlatformServiceConfigsDir)
+	require.Len(t, s.Extensions, 2)
+	assert.Equal(t, &fluxcd.FluxCD{
+		Namespace: "custom-flux",
+		Placement: extensions.Placement{Targets: []extensions.Target{
+			{Role: extensions.ClusterRolePlatform},
+			{Role: extensions.ClusterRoleMCP, MCP: "test"},
+		}},
+	}, s.Extensions[0])
+	assert.Equal(t, &helm.Helm{
+		Release:      "podinfo",
+		Chart:        filepath.Join("testdata", "charts", "podinfo"),
+		ValuesFiles:  []string{filepath.Join("testdata", "podinfo-values.yaml")},
+		Values:       map[string]interface{}{"replicaCount": float64(2)},
+		Dependencies: []string{"fluxcd"},
+	}, s.Extensions[1])
+}
+
+func TestLoadOpenMCPSetupInvalid(t *testing.T) {
+	_, err := LoadOpenMCPSetup("testdata/invalid-setup.yaml")
+	require.Error(t, err)
+	for _, msg := range []string{
+		"namespace must be set",
+		"operator: image must be set",
+		"clusterProviders[1]: duplicate name kind",
+		"extension unknown is not registered",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+}
@@ -0,0 +1,109 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+// Hooks are env.Funcs that run before and after the phases of Bootstrap and of the teardown.
+// The hooks of a hook point run in the order they have been added.
+//
+// During Bootstrap, a failing hook fails the phase. The hooks of a phase only run if the phase runs, i.e. if an
+// environment is reused, only the hooks of the verification phase run.
+//
+// The teardown deletes the components in reverse install order: service providers, platform services,
+// extensions, the onboarding cluster (platform cluster phase), cluster providers and finally the platform
+// kind cluster (cluster creation phase). Teardown hooks run even if a previous step failed, their errors are
+// recorded as teardown errors.
+type Hooks struct {
+	before         map[Phase][]env.Func
+	after          map[Phase][]env.Func
+	beforeTeardown map[Phase][]env.Func
+	afterTeardown  map[Phase][]env.Func
+}
+
+// BeforePhase adds hooks that run before the phase of Bootstrap
+func (h *Hooks) BeforePhase(phase Phase, fns ...env.Func) *Hooks {
+	h.before = addHooks(h.before, phase, fns)
+	return h
+}
+
+// AfterPhase adds hooks that run after the phase of Bootstrap succeeded
+func (h *Hooks) AfterPhase(phase Phase, fns ...env.Func) *Hooks {
+	h.after = addHooks(h.after, phase, fns)
+	return h
+}
+
+// BeforeTeardown adds hooks that run before the components of the phase are deleted
+func (h *Hooks) BeforeTeardown(phase Phase, fns ...env.Func) *Hooks {
+	h.beforeTeardown = addHooks(h.beforeTeardown, phase, fns)
+	return h
+}
+
+// AfterTeardown adds hooks that run after the components of the phase have been deleted
+func (h *Hooks) AfterTeardown(phase Phase, fns ...env.Func) *Hooks {
+	h.afterTeardown = addHooks(h.afterTeardown, phase, fns)
+	return h
+}
+
+func addHooks(hooks map[Phase][]env.Func, phase Phase, fns []env.Func) map[Phase][]env.Func {
+	if hooks == nil {
+		hooks = map[Phase][]env.Func{}
+	}
+	hooks[phase] = append(hooks[phase], fns...)
+	return hooks
+}
+
+// withHooks runs the before hooks of a phase, the step of the phase and the after hooks and stops at the first error
+func (h *Hooks) withHooks(phase Phase, fn env.Func) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		ctx, err := runHooks(ctx, c, h.before[phase], "before hook")
+		if err != nil {
+			return ctx, err
+		}
+		if ctx, err = fn(ctx, c); err != nil {
+			return ctx, err
+		}
+		return runHooks(ctx, c, h.after[phase], "after hook")
+	}
+}
+
+// withTeardownHooks runs the before teardown hooks of a phase, the teardown step of the phase and the after
+// teardown hooks. All of them run, their errors are joined.
+func (h *Hooks) withTeardownHooks(phase Phase, fn env.Func) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		errs := []error{}
+		for _, hook := range h.beforeTeardown[phase] {
+			var err error
+			if ctx, err = hook(ctx, c); err != nil {
+				errs = append(errs, fmt.Errorf("before teardown hook: %w", err))
+			}
+		}
+		ctx, err := fn(ctx, c)
+		errs = append(errs, err)
+		for _, hook := range h.afterTeardown[phase] {
+			var err error
+			if ctx, err = hook(ctx, c); err != nil {
+				errs = append(errs, fmt.Errorf("after teardown hook: %w", err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return ctx, fmt.Errorf("%s: %w", phase, err)
+		}
+		return ctx, nil
+	}
+}
+
+func runHooks(ctx context.Context, c *envconf.Config, hooks []env.Func, name string) (context.Context, error) {
+	for _, hook := range hooks {
+		var err error
+		if ctx, err = hook(ctx, c); err != nil {
+			return ctx, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return ctx, nil
+}
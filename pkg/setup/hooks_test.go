@@ -0,0 +1,84 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+func TestHooks(t *testing.T) {
+	calls := []string{}
+	record := func(name string, err error) env.Func {
+		return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+			calls = append(calls, name)
+			return ctx, err
+		}
+	}
+	tests := []struct {
+		name    string
+		hooks   func(h *Hooks)
+		run     func(h *Hooks) env.Func
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "phase hooks run in order",
+			hooks: func(h *Hooks) {
+				h.BeforePhase(PhaseOperator, record("before-1", nil), record("before-2", nil)).
+					AfterPhase(PhaseOperator, record("after", nil)).
+					BeforePhase(PhaseClusterProviders, record("other", nil))
+			},
+			run:  func(h *Hooks) env.Func { return h.withHooks(PhaseOperator, record("phase", nil)) },
+			want: []string{"before-1", "before-2", "phase", "after"},
+		},
+		{
+			name: "failing before hook stops the phase",
+			hooks: func(h *Hooks) {
+				h.BeforePhase(PhaseOperator, record("before", errors.New("boom"))).
+					AfterPhase(PhaseOperator, record("after", nil))
+			},
+			run:     func(h *Hooks) env.Func { return h.withHooks(PhaseOperator, record("phase", nil)) },
+			want:    []string{"before"},
+			wantErr: "before hook: boom",
+		},
+		{
+			name: "after hooks don't run if the phase fails",
+			hooks: func(h *Hooks) {
+				h.AfterPhase(PhaseOperator, record("after", nil))
+			},
+			run:     func(h *Hooks) env.Func { return h.withHooks(PhaseOperator, record("phase", errors.New("boom"))) },
+			want:    []string{"phase"},
+			wantErr: "boom",
+		},
+		{
+			name: "teardown hooks always run",
+			hooks: func(h *Hooks) {
+				h.BeforeTeardown(PhaseServiceProviders, record("before", errors.New("hook failed"))).
+					AfterTeardown(PhaseServiceProviders, record("after", nil))
+			},
+			run: func(h *Hooks) env.Func {
+				return h.withTeardownHooks(PhaseServiceProviders, record("teardown", errors.New("delete failed")))
+			},
+			want:    []string{"before", "teardown", "after"},
+			wantErr: "service-providers: before teardown hook: hook failed\ndelete failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = []string{}
+			h := &Hooks{}
+			tt.hooks(h)
+			_, err := tt.run(h)(context.Background(), envconf.New())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, calls)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+	"sigs.k8s.io/e2e-framework/support/kind"
+	kindv1alpha4 "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	dockerSocketHostPath      = "/var/run/docker.sock"
+	dockerSocketContainerPath = "/var/run/host-docker.sock"
+)
+
+// KindClusterSetup represents the configuration parameters of the platform kind cluster
+type KindClusterSetup struct {
+	// ConfigFile is an optional path to a kind cluster configuration that replaces the default configuration.
+	// All other settings are applied on top of it.
+	ConfigFile string `json:"configFile,omitempty"`
+	// NodeImage is the kind node image of every node and determines the Kubernetes version, e.g. kindest/node:v1.35.0
+	NodeImage string `json:"nodeImage,omitempty"`
+	// Workers is the number of worker nodes. If zero, the worker nodes of the configuration are kept.
+	Workers int `json:"workers,omitempty"`
+	// FeatureGates are the Kubernetes feature gates enabled or disabled on the cluster
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// RuntimeConfig is passed to the kube-apiserver --runtime-config flag
+	RuntimeConfig map[string]string `json:"runtimeConfig,omitempty"`
+	// ContainerdConfigPatches are merged into the containerd configuration of every node
+	ContainerdConfigPatches []string `json:"containerdConfigPatches,omitempty"`
+	// ExtraPortMappings are added to the first control-plane node
+	ExtraPortMappings []kindv1alpha4.PortMapping `json:"extraPortMappings,omitempty"`
+}
+
+// Config returns the kind cluster configuration. The docker socket mount that is required
+// by the kind cluster provider is always added to every node.
+func (k KindClusterSetup) Config() (*kindv1alpha4.Cluster, error) {
+	data, err := configFS.ReadFile("config/kind-config.yaml")
+	if err != nil {
+		return nil, err
+	}
+	if k.ConfigFile != "" {
+		if data, err = os.ReadFile(k.ConfigFile); err != nil {
+			return nil, fmt.Errorf("failed to read kind config %s: %w", k.ConfigFile, err)
+		}
+	}
+	cluster := &kindv1alpha4.Cluster{}
+	if err := yaml.UnmarshalStrict(data, cluster); err != nil {
+		return nil, fmt.Errorf("failed to parse kind config: %w", err)
+	}
+	controlPlane := -1
+	nodes := []kindv1alpha4.Node{}
+	workers := 0
+	for _, node := range cluster.Nodes {
+		if node.Role == kindv1alpha4.WorkerRole {
+			if k.Workers > 0 && workers == k.Workers {
+				continue
+			}
+			workers++
+		} else if controlPlane < 0 {
+			controlPlane = len(nodes)
+		}
+		nodes = append(nodes, node)
+	}
+	if controlPlane < 0 {
+		controlPlane = 0
+		nodes = append([]kindv1alpha4.Node{{Role: kindv1alpha4.ControlPlaneRole}}, nodes...)
+	}
+	for ; workers < k.Workers; workers++ {
+		nodes = append(nodes, kindv1alpha4.Node{Role: kindv1alpha4.WorkerRole})
+	}
+	for i := range nodes {
+		if k.NodeImage != "" {
+			nodes[i].Image = k.NodeImage
+		}
+		nodes[i].ExtraMounts = withDockerSocketMount(nodes[i].ExtraMounts)
+	}
+	nodes[controlPlane].ExtraPortMappings = append(nodes[controlPlane].ExtraPortMappings, k.ExtraPortMappings...)
+	cluster.Nodes = nodes
+	if len(k.FeatureGates) > 0 && cluster.FeatureGates == nil {
+		cluster.FeatureGates = map[string]bool{}
+	}
+	for gate, enabled := range k.FeatureGates {
+		cluster.FeatureGates[gate] = enabled
+	}
+	if len(k.RuntimeConfig) > 0 && cluster.RuntimeConfig == nil {
+		cluster.RuntimeConfig = map[string]string{}
+	}
+	for key, value := range k.RuntimeConfig {
+		cluster.RuntimeConfig[key] = value
+	}
+	cluster.ContainerdConfigPatches = append(cluster.ContainerdConfigPatches, k.ContainerdConfigPatches...)
+	cluster.Kind = "Cluster"
+	cluster.APIVersion = "kind.x-k8s.io/v1alpha4"
+	return cluster, nil
+}
+
+func withDockerSocketMount(mounts []kindv1alpha4.Mount) []kindv1alpha4.Mount {
+	for _, m := range mounts {
+		if m.HostPath == dockerSocketHostPath && m.ContainerPath == dockerSocketContainerPath {
+			return mounts
+		}
+	}
+	return append(mounts, kindv1alpha4.Mount{
+		HostPath:      dockerSocketHostPath,
+		ContainerPath: dockerSocketContainerPath,
+	})
+}
+
+// writeConfigFile writes the kind cluster configuration to a temporary file and returns its path
+func (k KindClusterSetup) writeConfigFile() (string, error) {
+	cluster, err := k.Config()
+	if err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(cluster)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "kind-config-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func (s *OpenMCPSetup) createPlatformCluster(name string) types.EnvFunc {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		klog.Info("create platform cluster...")
+		kindConfig, err := s.PlatformCluster.writeConfigFile()
+		if err != nil {
+			return ctx, fmt.Errorf("failed to render kind config: %w", err)
+		}
+		defer os.Remove(kindConfig)
+		return envfuncs.CreateClusterWithConfig(kind.NewProvider(), name, kindConfig)(ctx, c)
+	}
+}
@@ -0,0 +1,75 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kindv1alpha4 "sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+)
+
+func TestKindClusterSetupConfig(t *testing.T) {
+	dockerSocket := kindv1alpha4.Mount{HostPath: dockerSocketHostPath, ContainerPath: dockerSocketContainerPath}
+	tests := []struct {
+		name      string
+		setup     KindClusterSetup
+		wantRoles []kindv1alpha4.NodeRole
+		assert    func(t *testing.T, cluster *kindv1alpha4.Cluster)
+	}{
+		{
+			name:      "default configuration",
+			setup:     KindClusterSetup{},
+			wantRoles: []kindv1alpha4.NodeRole{kindv1alpha4.ControlPlaneRole},
+		},
+		{
+			name: "workers, node image and cluster settings",
+			setup: KindClusterSetup{
+				NodeImage:               "kindest/node:v1.35.0",
+				Workers:                 2,
+				FeatureGates:            map[string]bool{"MyFeature": true},
+				RuntimeConfig:           map[string]string{"api/alpha": "false"},
+				ContainerdConfigPatches: []string{"patch"},
+				ExtraPortMappings:       []kindv1alpha4.PortMapping{{ContainerPort: 80, HostPort: 8080}},
+			},
+			wantRoles: []kindv1alpha4.NodeRole{kindv1alpha4.ControlPlaneRole, kindv1alpha4.WorkerRole, kindv1alpha4.WorkerRole},
+			assert: func(t *testing.T, cluster *kindv1alpha4.Cluster) {
+				for _, node := range cluster.Nodes {
+					assert.Equal(t, "kindest/node:v1.35.0", node.Image)
+				}
+				assert.Equal(t, []kindv1alpha4.PortMapping{{ContainerPort: 80, HostPort: 8080}}, cluster.Nodes[0].ExtraPortMappings)
+				assert.Empty(t, cluster.Nodes[1].ExtraPortMappings)
+				assert.Equal(t, map[string]bool{"MyFeature": true}, cluster.FeatureGates)
+				assert.Equal(t, map[string]string{"api/alpha": "false"}, cluster.RuntimeConfig)
+				assert.Equal(t, []string{"patch"}, cluster.ContainerdConfigPatches)
+			},
+		},
+		{
+			name:      "custom configuration file",
+			setup:     KindClusterSetup{ConfigFile: "testdata/kind-config.yaml"},
+			wantRoles: []kindv1alpha4.NodeRole{kindv1alpha4.ControlPlaneRole, kindv1alpha4.WorkerRole, kindv1alpha4.WorkerRole},
+			assert: func(t *testing.T, cluster *kindv1alpha4.Cluster) {
+				assert.Equal(t, "10.100.0.0/16", cluster.Networking.PodSubnet)
+			},
+		},
+		{
+			name:      "custom configuration file with fewer workers",
+			setup:     KindClusterSetup{ConfigFile: "testdata/kind-config.yaml", Workers: 1},
+			wantRoles: []kindv1alpha4.NodeRole{kindv1alpha4.ControlPlaneRole, kindv1alpha4.WorkerRole},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster, err := tt.setup.Config()
+			require.NoError(t, err)
+			roles := []kindv1alpha4.NodeRole{}
+			for _, node := range cluster.Nodes {
+				roles = append(roles, node.Role)
+				assert.Contains(t, node.ExtraMounts, dockerSocket)
+			}
+			assert.Equal(t, tt.wantRoles, roles)
+			if tt.assert != nil {
+				tt.assert(t, cluster)
+			}
+		})
+	}
+}
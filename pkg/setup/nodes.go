@@ -0,0 +1,128 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+const nodeWatchInterval = 5 * time.Second
+
+// nodeHandler prepares a node of a kind cluster, e.g. by configuring containerd or importing images
+type nodeHandler struct {
+	name string
+	fn   func(ctx context.Context, node string) error
+}
+
+// nodeWatcher runs the registered node handlers once on every node of every kind cluster of a run,
+// including clusters that are created by the cluster provider later on
+type nodeWatcher struct {
+	runID string
+
+	mu       sync.Mutex
+	handlers []nodeHandler
+	handled  map[string]int
+	watching bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newNodeWatcher(runID string) *nodeWatcher {
+	return &nodeWatcher{
+		runID:   runID,
+		handled: map[string]int{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// register adds a node handler. Handlers run in the order of registration.
+func (w *nodeWatcher) register(name string, fn func(ctx context.Context, node string) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, nodeHandler{name: name, fn: fn})
+}
+
+// start runs the node handlers on all existing nodes of the run and keeps watching for new nodes
+func (w *nodeWatcher) start() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		w.mu.Lock()
+		noHandlers := len(w.handlers) == 0
+		w.mu.Unlock()
+		if noHandlers {
+			return ctx, nil
+		}
+		if err := w.sync(ctx); err != nil {
+			return ctx, err
+		}
+		w.mu.Lock()
+		w.watching = true
+		w.mu.Unlock()
+		go w.watch()
+		return ctx, nil
+	}
+}
+
+func (w *nodeWatcher) watch() {
+	defer close(w.done)
+	ticker := time.NewTicker(nodeWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.sync(context.Background()); err != nil {
+				klog.Warningf("prepare kind nodes failed: %v", err)
+			}
+		}
+	}
+}
+
+// stopWatching stops watching for new nodes
+func (w *nodeWatcher) stopWatching() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		w.mu.Lock()
+		watching := w.watching
+		w.watching = false
+		w.mu.Unlock()
+		if watching {
+			close(w.stop)
+			<-w.done
+		}
+		return ctx, nil
+	}
+}
+
+// sync runs the node handlers that have not succeeded yet on every node of the run's clusters
+func (w *nodeWatcher) sync(ctx context.Context) error {
+	clusters, err := clusterutils.RunClusterNames(w.runID)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, cluster := range clusters {
+		nodes, err := kindNodes(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			for i := w.handled[node]; i < len(w.handlers); i++ {
+				h := w.handlers[i]
+				if err := h.fn(ctx, node); err != nil {
+					return fmt.Errorf("%s on node %s failed: %w", h.name, node, err)
+				}
+				w.handled[node] = i + 1
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package setup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/internal"
+)
+
+// OfflineSetup configures the pre-seeding of images for environments without access to image registries.
+// All images of the image manifest are imported into every node of every kind cluster of the run,
+// including clusters created later by the cluster provider.
+// Images that are pulled with pull policy Always, e.g. images with tag latest, still require registry access.
+type OfflineSetup struct {
+	// Enabled enables the pre-seeding of images
+	Enabled bool `json:"enabled,omitempty"`
+	// ImageArchiveDir is a directory with image archives created by docker save. Images that are not found
+	// in an archive are taken from the local docker image cache.
+	ImageArchiveDir string `json:"imageArchiveDir,omitempty"`
+	// Images are images in addition to the component images, e.g. images referenced by platform service configs
+	Images []string `json:"images,omitempty"`
+	// ImageListFiles are files listing additional images, one image per line. Empty lines and lines starting with # are ignored.
+	ImageListFiles []string `json:"imageListFiles,omitempty"`
+}
+
+// ImageManifest returns the sorted list of all images of the environment, i.e. the images of all components
+// and the additional images of the offline setup
+func (s *OpenMCPSetup) ImageManifest() ([]string, error) {
+	images := []string{s.Operator.Image}
+	for _, cp := range s.ClusterProviders {
+		images = append(images, cp.Image)
+	}
+	for _, ps := range s.PlatformServices {
+		images = append(images, ps.Image)
+	}
+	for _, sp := range s.ServiceProviders {
+		images = append(images, sp.Image)
+	}
+	images = append(images, s.Offline.Images...)
+	for _, file := range s.Offline.ImageListFiles {
+		listed, err := readImageList(file)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, listed...)
+	}
+	images = slices.DeleteFunc(images, func(image string) bool { return image == "" })
+	return slices.Compact(slices.Sorted(slices.Values(images))), nil
+}
+
+func readImageList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image list: %w", err)
+	}
+	defer f.Close()
+	images := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		images = append(images, line)
+	}
+	return images, scanner.Err()
+}
+
+// imageSeeder imports the images of the image manifest into every node of the run
+type imageSeeder struct {
+	setup   *OpenMCPSetup
+	watcher *nodeWatcher
+
+	archives map[string]string
+	tmpDir   string
+}
+
+func newImageSeeder(setup *OpenMCPSetup, watcher *nodeWatcher) *imageSeeder {
+	return &imageSeeder{
+		setup:    setup,
+		watcher:  watcher,
+		archives: map[string]string{},
+	}
+}
+
+// prepare finds an archive for every image of the image manifest and fails if an image is not available locally.
+// Images of the local docker image cache are saved to temporary archives.
+func (i *imageSeeder) prepare() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if !i.setup.Offline.Enabled {
+			return ctx, nil
+		}
+		images, err := i.setup.ImageManifest()
+		if err != nil {
+			return ctx, err
+		}
+		klog.Infof("pre-seeding %d images", len(images))
+		archived := map[string]string{}
+		if dir := i.setup.Offline.ImageArchiveDir; dir != "" {
+			if archived, err = indexImageArchives(dir); err != nil {
+				return ctx, err
+			}
+		}
+		if i.tmpDir, err = os.MkdirTemp("", "openmcp-images-*"); err != nil {
+			return ctx, err
+		}
+		missing := []string{}
+		saves := []func() error{}
+		for n, image := range images {
+			if archive, ok := archived[normalizeImage(image)]; ok {
+				i.archives[image] = archive
+				continue
+			}
+			if _, err := docker(ctx, nil, "image", "inspect", image); err != nil {
+				missing = append(missing, image)
+				continue
+			}
+			archive := filepath.Join(i.tmpDir, fmt.Sprintf("image-%d.tar", n))
+			i.archives[image] = archive
+			saves = append(saves, func() error {
+				_, err := docker(ctx, nil, "save", "-o", archive, image)
+				return err
+			})
+		}
+		if len(missing) > 0 {
+			return ctx, fmt.Errorf("images not available locally: %s", strings.Join(missing, ", "))
+		}
+		if err := internal.RunConcurrently(i.setup.Concurrency, saves...); err != nil {
+			return ctx, err
+		}
+		i.watcher.register("import images", i.importImages)
+		return ctx, nil
+	}
+}
+
+// importImages imports all images of the image manifest into the node
+func (i *imageSeeder) importImages(ctx context.Context, node string) error {
+	archives := slices.Compact(slices.Sorted(maps.Values(i.archives)))
+	imports := []func() error{}
+	for _, archive := range archives {
+		imports = append(imports, func() error {
+			f, err := os.Open(archive)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := docker(ctx, f, "exec", "-i", node,
+				"ctr", "--namespace=k8s.io", "images", "import", "--all-platforms", "--digests", "-"); err != nil {
+				return fmt.Errorf("import image archive %s: %w", filepath.Base(archive), err)
+			}
+			return nil
+		})
+	}
+	if err := internal.RunConcurrently(i.setup.Concurrency, imports...); err != nil {
+		return err
+	}
+	klog.Infof("imported %d images into node %s", len(i.archives), node)
+	return nil
+}
+
+// cleanup removes the temporary image archives
+func (i *imageSeeder) cleanup() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if i.tmpDir != "" {
+			os.RemoveAll(i.tmpDir)
+		}
+		return ctx, nil
+	}
+}
+
+// indexImageArchives returns the archive path of every image tagged in the archives of the directory
+func indexImageArchives(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image archive dir: %w", err)
+	}
+	index := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".tar" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		tags, err := archiveRepoTags(path)
+		if err != nil {
+			return nil, fmt.Errorf("image archive %s: %w", path, err)
+		}
+		for _, tag := range tags {
+			index[normalizeImage(tag)] = path
+		}
+	}
+	return index, nil
+}
+
+// archiveRepoTags returns the image tags of an archive created by docker save
+func archiveRepoTags(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := tar.NewReader(f)
+	for {
+		header, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("manifest.json not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name != "manifest.json" {
+			continue
+		}
+		manifest := []struct {
+			RepoTags []string `json:"RepoTags"`
+		}{}
+		if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+			return nil, err
+		}
+		tags := []string{}
+		for _, m := range manifest {
+			tags = append(tags, m.RepoTags...)
+		}
+		return tags, nil
+	}
+}
+
+// normalizeImage returns the fully qualified reference of an image, e.g. docker.io/library/busybox:latest for busybox
+func normalizeImage(image string) string {
+	if !strings.Contains(image, "@") && strings.LastIndex(image, ":") <= strings.LastIndex(image, "/") {
+		image += ":latest"
+	}
+	host, _, found := strings.Cut(image, "/")
+	if !found {
+		return "docker.io/library/" + image
+	}
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "docker.io/" + image
+	}
+	return image
+}
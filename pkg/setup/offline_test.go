@@ -0,0 +1,67 @@
+package setup
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+func TestImageManifest(t *testing.T) {
+	s := &OpenMCPSetup{
+		Operator: OpenMCPOperatorSetup{Image: "ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0"},
+		ClusterProviders: []providers.ClusterProviderSetup{
+			{Name: "kind", Image: "ghcr.io/openmcp-project/images/cluster-provider-kind:v0.4.1"},
+		},
+		Offline: OfflineSetup{
+			Images:         []string{"busybox:1.37"},
+			ImageListFiles: []string{"testdata/images.txt"},
+		},
+	}
+	images, err := s.ImageManifest()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"busybox:1.37",
+		"docker.io/envoyproxy/envoy:distroless-v1.35.0",
+		"ghcr.io/openmcp-project/images/cluster-provider-kind:v0.4.1",
+		"ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0",
+	}, images)
+}
+
+func TestNormalizeImage(t *testing.T) {
+	tests := map[string]string{
+		"busybox":                     "docker.io/library/busybox:latest",
+		"busybox:1.37":                "docker.io/library/busybox:1.37",
+		"envoyproxy/envoy:v1.35.0":    "docker.io/envoyproxy/envoy:v1.35.0",
+		"ghcr.io/openmcp-project/x":   "ghcr.io/openmcp-project/x:latest",
+		"localhost:5001/kind:dev":     "localhost:5001/kind:dev",
+		"docker.io/library/busybox:1": "docker.io/library/busybox:1",
+	}
+	for image, want := range tests {
+		assert.Equal(t, want, normalizeImage(image), image)
+	}
+}
+
+func TestIndexImageArchives(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "images.tar"))
+	require.NoError(t, err)
+	w := tar.NewWriter(f)
+	manifest := []byte(`[{"RepoTags":["busybox:1.37","ghcr.io/openmcp-project/images/kind:dev"]}]`)
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0o644, Size: int64(len(manifest))}))
+	_, err = w.Write(manifest)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+	index, err := indexImageArchives(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"docker.io/library/busybox:1.37":          filepath.Join(dir, "images.tar"),
+		"ghcr.io/openmcp-project/images/kind:dev": filepath.Join(dir, "images.tar"),
+	}, index)
+}
@@ -0,0 +1,85 @@
+package setup
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// operatorVerbosities are the log levels supported by the openmcp-operator
+var operatorVerbosities = []string{"DEBUG", "INFO", "ERROR"}
+
+// operatorTemplateData is passed to the operator template. Structured values are JSON encoded,
+// which the template embeds as YAML flow style.
+type operatorTemplateData struct {
+	OpenMCPOperatorSetup
+	// ConfigData is the rendered operator configuration indented for the ConfigMap
+	ConfigData string
+	// ReplicaCount is the number of replicas of the Deployment
+	ReplicaCount int32
+	// ExtraInitArgs and ExtraArgs are the additional args of the init and the operator container
+	ExtraInitArgs []string
+	ExtraArgs     []string
+	// ExtraEnv are the additional environment variables of both containers
+	ExtraEnv []string
+	// ResourceRequirements are the resources of both containers
+	ResourceRequirements string
+	// PullSecrets are the image pull secrets of the pod
+	PullSecrets []string
+}
+
+// templateData returns the data of the operator template for a run
+func (o OpenMCPOperatorSetup) templateData(runID string) (operatorTemplateData, error) {
+	if o.Verbosity != "" && !slices.Contains(operatorVerbosities, o.Verbosity) {
+		return operatorTemplateData{}, fmt.Errorf("invalid operator verbosity %q, must be one of %v", o.Verbosity, operatorVerbosities)
+	}
+	config, err := o.renderConfig(runID)
+	if err != nil {
+		return operatorTemplateData{}, fmt.Errorf("failed to render operator config: %w", err)
+	}
+	data := operatorTemplateData{
+		OpenMCPOperatorSetup: o,
+		ConfigData:           indent(config, 4),
+		ReplicaCount:         o.Replicas,
+	}
+	if data.ReplicaCount == 0 {
+		data.ReplicaCount = 1
+	}
+	initArgs := o.InitArgs
+	args := o.Args
+	if o.Verbosity != "" {
+		initArgs = append([]string{"--verbosity", o.Verbosity}, initArgs...)
+		args = append([]string{"--verbosity", o.Verbosity}, args...)
+	}
+	if data.ExtraInitArgs, err = toJSONList(initArgs); err != nil {
+		return data, err
+	}
+	if data.ExtraArgs, err = toJSONList(args); err != nil {
+		return data, err
+	}
+	if data.ExtraEnv, err = toJSONList(o.Env); err != nil {
+		return data, err
+	}
+	if data.PullSecrets, err = toJSONList(o.ImagePullSecrets); err != nil {
+		return data, err
+	}
+	resources, err := json.Marshal(o.Resources)
+	if err != nil {
+		return data, err
+	}
+	data.ResourceRequirements = string(resources)
+	return data, nil
+}
+
+// toJSONList returns the JSON encoding of every item
+func toJSONList[T any](items []T) ([]string, error) {
+	encoded := make([]string, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, string(data))
+	}
+	return encoded, nil
+}
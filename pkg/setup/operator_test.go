@@ -0,0 +1,88 @@
+package setup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/internal"
+)
+
+func renderOperatorDeployment(t *testing.T, operator OpenMCPOperatorSetup) *appsv1.Deployment {
+	t.Helper()
+	tmpl, err := configFS.ReadFile("config/operator.yaml.tmpl")
+	require.NoError(t, err)
+	data, err := operator.templateData("abc")
+	require.NoError(t, err)
+	manifests, err := internal.ExecTemplate(string(tmpl), data)
+	require.NoError(t, err)
+	for _, doc := range strings.Split(manifests, "\n---\n") {
+		if !strings.Contains(doc, "kind: Deployment") {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		require.NoError(t, yaml.UnmarshalStrict([]byte(doc), deployment))
+		return deployment
+	}
+	t.Fatal("no deployment rendered")
+	return nil
+}
+
+func TestOperatorDeploymentDefaults(t *testing.T) {
+	deployment := renderOperatorDeployment(t, OpenMCPOperatorSetup{
+		Name:        "openmcp-operator",
+		Namespace:   "openmcp-system",
+		Image:       "openmcp-operator:dev",
+		Environment: "debug",
+	})
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	pod := deployment.Spec.Template.Spec
+	assert.Empty(t, pod.ImagePullSecrets)
+	assert.Equal(t, []string{"run", "--environment", "debug", "--config", "/etc/openmcp-operator/config"}, pod.Containers[0].Args)
+	assert.Len(t, pod.Containers[0].Env, 4)
+	assert.Empty(t, pod.Containers[0].ImagePullPolicy)
+}
+
+func TestOperatorDeploymentCustomization(t *testing.T) {
+	deployment := renderOperatorDeployment(t, OpenMCPOperatorSetup{
+		Name:        "openmcp-operator",
+		Namespace:   "openmcp-system",
+		Image:       "openmcp-operator:dev",
+		Environment: "debug",
+		InitArgs:    []string{"--init-flag"},
+		Args:        []string{"--leader-elect=true"},
+		Env:         []corev1.EnvVar{{Name: "FOO", Value: "bar: baz"}},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		Replicas:         2,
+		ImagePullPolicy:  corev1.PullNever,
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		Verbosity:        "DEBUG",
+	})
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	pod := deployment.Spec.Template.Spec
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, pod.ImagePullSecrets)
+	initContainer := pod.InitContainers[0]
+	assert.Equal(t, []string{"init", "--environment", "debug", "--config", "/etc/openmcp-operator/config",
+		"--verbosity", "DEBUG", "--init-flag"}, initContainer.Args)
+	container := pod.Containers[0]
+	assert.Equal(t, []string{"run", "--environment", "debug", "--config", "/etc/openmcp-operator/config",
+		"--verbosity", "DEBUG", "--leader-elect=true"}, container.Args)
+	for _, c := range []corev1.Container{initContainer, container} {
+		assert.Equal(t, corev1.PullNever, c.ImagePullPolicy)
+		assert.Equal(t, corev1.EnvVar{Name: "FOO", Value: "bar: baz"}, c.Env[len(c.Env)-1])
+		assert.Equal(t, "256Mi", c.Resources.Limits.Memory().String())
+	}
+}
+
+func TestOperatorInvalidVerbosity(t *testing.T) {
+	_, err := OpenMCPOperatorSetup{Verbosity: "TRACE"}.templateData("abc")
+	assert.ErrorContains(t, err, "invalid operator verbosity")
+}
@@ -0,0 +1,154 @@
+package setup
+
+import (
+	"encoding/json"
+	"strings"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+// OperatorConfig is the typed configuration of the openmcp-operator. Fields that are not set keep their defaults.
+type OperatorConfig struct {
+	ManagedControlPlane *ManagedControlPlaneConfig `json:"managedControlPlane,omitempty"`
+	Scheduler           *SchedulerConfig           `json:"scheduler,omitempty"`
+}
+
+// ManagedControlPlaneConfig configures the managed control plane controller of the openmcp-operator
+type ManagedControlPlaneConfig struct {
+	// MCPClusterPurpose is the purpose of the clusters requested for MCPs, defaults to mcp
+	MCPClusterPurpose string `json:"mcpClusterPurpose,omitempty"`
+}
+
+// SchedulerConfig configures the cluster scheduler of the openmcp-operator
+type SchedulerConfig struct {
+	// Scope is the scope of the scheduler, either Cluster or Namespaced, defaults to Cluster
+	Scope string `json:"scope,omitempty"`
+	// PurposeMappings are added to the default purpose mappings and replace mappings of the same purpose.
+	// They are applied after ExtraClusterPurposeMapping.
+	PurposeMappings []providers.ClusterPurposeMapping `json:"purposeMappings,omitempty"`
+}
+
+// defaultPurposeMappings are the purpose mappings of the kind cluster provider
+var defaultPurposeMappings = []providers.ClusterPurposeMapping{
+	{
+		Purpose: "mcp",
+		Profile: "kind",
+		Tenancy: clustersv1alpha1.TENANCY_EXCLUSIVE,
+	},
+	{
+		Purpose: "platform",
+		Profile: "kind",
+		Tenancy: clustersv1alpha1.TENANCY_SHARED,
+	},
+	{
+		Purpose: onboardingClusterPrefix,
+		Profile: "kind",
+		Tenancy: clustersv1alpha1.TENANCY_SHARED,
+	},
+	{
+		Purpose: "workload",
+		Profile: "kind",
+		Tenancy: clustersv1alpha1.TENANCY_SHARED,
+	},
+}
+
+// renderConfig returns the operator configuration of a run. The typed config is merged into the defaults,
+// the raw config is merged last.
+func (o OpenMCPOperatorSetup) renderConfig(runID string) (string, error) {
+	mappings := append([]providers.ClusterPurposeMapping{}, defaultPurposeMappings...)
+	mappings = append(mappings, o.ExtraClusterPurposeMapping...)
+	config := map[string]interface{}{
+		"managedControlPlane": map[string]interface{}{
+			"mcpClusterPurpose": "mcp",
+		},
+		"scheduler": map[string]interface{}{
+			"scope": "Cluster",
+		},
+	}
+	if o.Config != nil {
+		if o.Config.Scheduler != nil {
+			mappings = append(mappings, o.Config.Scheduler.PurposeMappings...)
+		}
+		typed, err := toMap(o.Config)
+		if err != nil {
+			return "", err
+		}
+		if scheduler, ok := typed["scheduler"].(map[string]interface{}); ok {
+			delete(scheduler, "purposeMappings")
+		}
+		mergeConfig(config, typed)
+	}
+	purposeMappings := map[string]interface{}{}
+	for _, m := range mappings {
+		purposeMappings[m.Purpose] = purposeMapping(m, runID)
+	}
+	config["scheduler"].(map[string]interface{})["purposeMappings"] = purposeMappings
+	mergeConfig(config, o.RawConfig)
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// purposeMapping returns the scheduler purpose mapping whose cluster template labels the clusters with the run ID
+// and names the kind clusters of the run after the purpose
+func purposeMapping(m providers.ClusterPurposeMapping, runID string) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"labels": map[string]interface{}{
+			clusterutils.RunIDLabel: runID,
+		},
+	}
+	if m.Tenancy == clustersv1alpha1.TENANCY_SHARED {
+		metadata["annotations"] = map[string]interface{}{
+			"kind.clusters.openmcp.cloud/name": m.Purpose + "-" + runID,
+		}
+	} else {
+		metadata["generateName"] = m.Purpose + "-" + runID + "-"
+	}
+	return map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": metadata,
+			"spec": map[string]interface{}{
+				"profile": m.Profile,
+				"tenancy": string(m.Tenancy),
+			},
+		},
+	}
+}
+
+// mergeConfig merges src into dst. Nested maps are merged, all other values of src replace the values of dst.
+func mergeConfig(dst map[string]interface{}, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func toMap(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(data, &m)
+}
+
+// indent indents every line of s by the passed in number of spaces
+func indent(s string, spaces int) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
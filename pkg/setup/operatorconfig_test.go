@@ -0,0 +1,54 @@
+package setup
+
+import (
+	"testing"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+func TestRenderConfig(t *testing.T) {
+	operator := OpenMCPOperatorSetup{
+		ExtraClusterPurposeMapping: []providers.ClusterPurposeMapping{
+			{Purpose: "workload", Profile: "kind", Tenancy: clustersv1alpha1.TENANCY_EXCLUSIVE},
+		},
+		Config: &OperatorConfig{
+			ManagedControlPlane: &ManagedControlPlaneConfig{MCPClusterPurpose: "mcp-worker"},
+			Scheduler: &SchedulerConfig{
+				Scope: "Namespaced",
+				PurposeMappings: []providers.ClusterPurposeMapping{
+					{Purpose: "mcp-worker", Profile: "kind", Tenancy: clustersv1alpha1.TENANCY_SHARED},
+				},
+			},
+		},
+		RawConfig: map[string]interface{}{
+			"scheduler": map[string]interface{}{
+				"strategy": "Balanced",
+			},
+		},
+	}
+	data, err := operator.renderConfig("abc")
+	require.NoError(t, err)
+	config := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal([]byte(data), &config))
+
+	get := func(fields ...string) interface{} {
+		value, found, err := unstructured.NestedFieldNoCopy(config, fields...)
+		require.NoError(t, err)
+		require.True(t, found, fields)
+		return value
+	}
+	assert.Equal(t, "mcp-worker", get("managedControlPlane", "mcpClusterPurpose"))
+	assert.Equal(t, "Namespaced", get("scheduler", "scope"))
+	assert.Equal(t, "Balanced", get("scheduler", "strategy"))
+	assert.Len(t, get("scheduler", "purposeMappings"), 5)
+	assert.Equal(t, "Exclusive", get("scheduler", "purposeMappings", "workload", "template", "spec", "tenancy"))
+	assert.Equal(t, "workload-abc-", get("scheduler", "purposeMappings", "workload", "template", "metadata", "generateName"))
+	assert.Equal(t, "mcp-worker-abc", get("scheduler", "purposeMappings", "mcp-worker", "template", "metadata", "annotations", "kind.clusters.openmcp.cloud/name"))
+	assert.Equal(t, "abc", get("scheduler", "purposeMappings", "platform", "template", "metadata", "labels", "testing.openmcp.cloud/run-id"))
+}
@@ -0,0 +1,110 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+const (
+	imageEnvPrefix     = "OPENMCP_IMAGE_"
+	versionEnvPrefix   = "OPENMCP_VERSION_"
+	loadImageEnvPrefix = "OPENMCP_LOAD_IMAGE_"
+)
+
+// ImageEnvVar returns the name of the environment variable that overrides the image of a component,
+// e.g. OPENMCP_IMAGE_OPENMCP_OPERATOR for the component openmcp-operator
+func ImageEnvVar(componentName string) string {
+	return imageEnvPrefix + envVarSuffix(componentName)
+}
+
+// VersionEnvVar returns the name of the environment variable that overrides the image tag of a component,
+// e.g. OPENMCP_VERSION_KIND for the component kind
+func VersionEnvVar(componentName string) string {
+	return versionEnvPrefix + envVarSuffix(componentName)
+}
+
+// LoadImageEnvVar returns the name of the environment variable that overrides the LoadImageToCluster
+// setting of a component, e.g. OPENMCP_LOAD_IMAGE_CROSSPLANE for the component crossplane
+func LoadImageEnvVar(componentName string) string {
+	return loadImageEnvPrefix + envVarSuffix(componentName)
+}
+
+func envVarSuffix(componentName string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, componentName)
+}
+
+// applyOverrides applies the image, version and load image overrides from the environment
+// to every component and logs the effective images
+func (s *OpenMCPSetup) applyOverrides() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		errs := []error{}
+		override := func(kind string, name string, image *string, loadImage *bool) {
+			if err := overrideComponent(name, image, loadImage); err != nil {
+				errs = append(errs, err)
+			}
+			klog.Infof("%s %s: image %s, load image to cluster: %t", kind, name, *image, *loadImage)
+		}
+		override("operator", s.Operator.Name, &s.Operator.Image, &s.Operator.LoadImageToCluster)
+		for i := range s.ClusterProviders {
+			cp := &s.ClusterProviders[i]
+			override("cluster provider", cp.Name, &cp.Image, &cp.LoadImageToCluster)
+		}
+		for i := range s.ServiceProviders {
+			sp := &s.ServiceProviders[i]
+			override("service provider", sp.Name, &sp.Image, &sp.LoadImageToCluster)
+		}
+		for i := range s.PlatformServices {
+			ps := &s.PlatformServices[i]
+			override("platform service", ps.Name, &ps.Image, &ps.LoadImageToCluster)
+		}
+		if err := errors.Join(errs...); err != nil {
+			return ctx, fmt.Errorf("invalid overrides: %w", err)
+		}
+		return ctx, nil
+	}
+}
+
+func overrideComponent(name string, image *string, loadImage *bool) error {
+	if value, ok := os.LookupEnv(ImageEnvVar(name)); ok && value != "" {
+		*image = value
+	}
+	if value, ok := os.LookupEnv(VersionEnvVar(name)); ok && value != "" {
+		*image = withTag(*image, value)
+	}
+	if value, ok := os.LookupEnv(LoadImageEnvVar(name)); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", LoadImageEnvVar(name), err)
+		}
+		*loadImage = enabled
+	}
+	return nil
+}
+
+// withTag replaces the tag and digest of an image reference
+func withTag(image string, tag string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
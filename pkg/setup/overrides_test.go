@@ -0,0 +1,68 @@
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/platformservices"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	t.Setenv("OPENMCP_IMAGE_OPENMCP_OPERATOR", "localhost/openmcp-operator:dev")
+	t.Setenv("OPENMCP_LOAD_IMAGE_OPENMCP_OPERATOR", "true")
+	t.Setenv("OPENMCP_VERSION_KIND", "v0.5.0")
+	t.Setenv("OPENMCP_IMAGE_CROSSPLANE", "localhost/service-provider-crossplane:candidate")
+	s := &OpenMCPSetup{
+		Operator: OpenMCPOperatorSetup{
+			Name:  "openmcp-operator",
+			Image: "ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0",
+		},
+		ClusterProviders: []providers.ClusterProviderSetup{
+			{Name: "kind", Image: "ghcr.io/openmcp-project/images/cluster-provider-kind:v0.4.1"},
+		},
+		ServiceProviders: []providers.ServiceProviderSetup{
+			{Name: "crossplane", Image: "ghcr.io/openmcp-project/images/service-provider-crossplane:v1.0.0"},
+		},
+		PlatformServices: []platformservices.PlatformServiceSetup{
+			{Name: "gateway", Image: "ghcr.io/openmcp-project/images/platform-service-gateway:v0.0.10", LoadImageToCluster: true},
+		},
+	}
+	_, err := s.applyOverrides()(context.Background(), envconf.New())
+	require.NoError(t, err)
+	assert.Equal(t, "localhost/openmcp-operator:dev", s.Operator.Image)
+	assert.True(t, s.Operator.LoadImageToCluster)
+	assert.Equal(t, "ghcr.io/openmcp-project/images/cluster-provider-kind:v0.5.0", s.ClusterProviders[0].Image)
+	assert.Equal(t, "localhost/service-provider-crossplane:candidate", s.ServiceProviders[0].Image)
+	assert.Equal(t, "ghcr.io/openmcp-project/images/platform-service-gateway:v0.0.10", s.PlatformServices[0].Image)
+	assert.True(t, s.PlatformServices[0].LoadImageToCluster)
+}
+
+func TestApplyOverridesInvalidLoadImage(t *testing.T) {
+	t.Setenv("OPENMCP_LOAD_IMAGE_KIND", "maybe")
+	s := &OpenMCPSetup{
+		ClusterProviders: []providers.ClusterProviderSetup{{Name: "kind"}},
+	}
+	_, err := s.applyOverrides()(context.Background(), envconf.New())
+	assert.ErrorContains(t, err, "OPENMCP_LOAD_IMAGE_KIND")
+}
+
+func TestWithTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0", want: "ghcr.io/openmcp-project/images/openmcp-operator:v2"},
+		{image: "localhost:5001/openmcp-operator", want: "localhost:5001/openmcp-operator:v2"},
+		{image: "openmcp-operator@sha256:abc", want: "openmcp-operator:v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			assert.Equal(t, tt.want, withTag(tt.image, "v2"))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/diagnostics"
+)
+
+// Phase identifies a step of Bootstrap
+type Phase string
+
+const (
+	PhaseClusterCreation  Phase = "cluster-creation"
+	PhaseImageLoading     Phase = "image-loading"
+	PhaseOperator         Phase = "operator"
+	PhaseClusterProviders Phase = "cluster-providers"
+	PhasePlatformCluster  Phase = "platform-cluster"
+	PhaseExtensions       Phase = "extensions"
+	PhaseVerification     Phase = "verification"
+	PhasePlatformServices Phase = "platform-services"
+	PhaseServiceProviders Phase = "service-providers"
+)
+
+// bootstrapArtifactsDir is the subdirectory of the artifacts directory used for failures during Bootstrap
+const bootstrapArtifactsDir = "bootstrap"
+
+// phase wraps the step of a phase, runs the hooks of the phase, records its duration and collects diagnostics
+// if the step fails
+func (s *OpenMCPSetup) phase(phase Phase, platformClusterName string, fn env.Func) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		start := time.Now()
+		ctx, err := s.Hooks.withHooks(phase, fn)(ctx, c)
+		s.timings.phase(phase, start, err != nil)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", phase, err)
+			s.collectDiagnostics(ctx, c, platformClusterName, filepath.Join(bootstrapArtifactsDir, string(phase)))
+		}
+		return ctx, err
+	}
+}
+
+// failedBeforeFeatureKey is the context key of the failure state of the test before the feature with the name ran
+type failedBeforeFeatureKey string
+
+// recordFailureState records whether the test has already failed before a feature runs. The feature hooks get
+// the *testing.T of the test, not of the feature, which stays failed once an earlier feature failed.
+func recordFailureState() types.FeatureEnvFunc {
+	return func(ctx context.Context, _ *envconf.Config, t *testing.T, f types.Feature) (context.Context, error) {
+		return withFailureState(ctx, f, t.Failed()), nil
+	}
+}
+
+// collectDiagnosticsOnFailure collects diagnostics after a feature that failed the test
+func (s *OpenMCPSetup) collectDiagnosticsOnFailure(platformClusterName string) types.FeatureEnvFunc {
+	return func(ctx context.Context, c *envconf.Config, t *testing.T, f types.Feature) (context.Context, error) {
+		if featureFailed(ctx, f, t.Failed()) {
+			s.collectDiagnostics(ctx, c, platformClusterName, filepath.Join(diagnostics.DirName(t.Name()), diagnostics.DirName(f.Name())))
+		}
+		return ctx, nil
+	}
+}
+
+func withFailureState(ctx context.Context, f types.Feature, failed bool) context.Context {
+	return context.WithValue(ctx, failedBeforeFeatureKey(f.Name()), failed)
+}
+
+// featureFailed returns true if the test has failed while the feature ran
+func featureFailed(ctx context.Context, f types.Feature, failed bool) bool {
+	failedBefore, _ := ctx.Value(failedBeforeFeatureKey(f.Name())).(bool)
+	return failed && !failedBefore
+}
+
+func (s *OpenMCPSetup) collectDiagnostics(ctx context.Context, c *envconf.Config, platformClusterName string, subDir string) {
+	dir := diagnostics.ArtifactsDir(s.ArtifactsDir)
+	if dir == "" {
+		return
+	}
+	if err := diagnostics.CollectAll(ctx, c, platformClusterName, filepath.Join(dir, subDir)); err != nil {
+		klog.Errorf("failed to collect diagnostics: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+)
+
+func TestFeatureFailed(t *testing.T) {
+	first := features.New("first").Feature()
+	failing := features.New("failing").Feature()
+	passing := features.New("passing").Feature()
+
+	ctx := withFailureState(context.Background(), first, false)
+	assert.False(t, featureFailed(ctx, first, false))
+	ctx = withFailureState(ctx, failing, false)
+	assert.True(t, featureFailed(ctx, failing, true))
+	// the test stays failed after the failing feature
+	ctx = withFailureState(ctx, passing, true)
+	assert.False(t, featureFailed(ctx, passing, true))
+}
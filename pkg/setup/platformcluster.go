@@ -0,0 +1,100 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	clustersv1alpha1 "github.com/openmcp-project/openmcp-operator/api/clusters/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+// platformClusterBuilder builds the platform Cluster object of a run for the kind cluster with the passed in name
+type platformClusterBuilder func(namespace string, runID string, platformClusterName string) *unstructured.Unstructured
+
+// platformClusterBuilders contains a platformClusterBuilder per supported cluster provider
+var platformClusterBuilders = map[string]platformClusterBuilder{
+	"kind": kindPlatformCluster,
+}
+
+func kindPlatformCluster(namespace string, runID string, platformClusterName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "clusters.openmcp.cloud/v1alpha1",
+			"kind":       "Cluster",
+			"metadata": map[string]interface{}{
+				"name":      "platform",
+				"namespace": namespace,
+				"labels": map[string]interface{}{
+					clusterutils.RunIDLabel: runID,
+				},
+				"annotations": map[string]interface{}{
+					"kind.clusters.openmcp.cloud/name": platformClusterName,
+				},
+			},
+			"spec": map[string]interface{}{
+				"kubernetes": map[string]interface{}{},
+				"profile":    "kind",
+				"purposes": []interface{}{
+					clustersv1alpha1.PURPOSE_PLATFORM,
+				},
+				"tenancy": string(clustersv1alpha1.TENANCY_SHARED),
+			},
+		},
+	}
+}
+
+// platformClusterProvider returns the cluster provider that manages the platform cluster
+func (s *OpenMCPSetup) platformClusterProvider() (providers.ClusterProviderSetup, error) {
+	if len(s.ClusterProviders) == 0 {
+		return providers.ClusterProviderSetup{}, fmt.Errorf("no cluster providers found")
+	}
+	name := s.PlatformClusterProvider
+	if name == "" {
+		if len(s.ClusterProviders) > 1 {
+			return providers.ClusterProviderSetup{}, fmt.Errorf("%d cluster providers configured, PlatformClusterProvider must be set", len(s.ClusterProviders))
+		}
+		name = s.ClusterProviders[0].Name
+	}
+	if _, ok := platformClusterBuilders[name]; !ok {
+		return providers.ClusterProviderSetup{}, fmt.Errorf("platform cluster provider %s is not supported, supported providers: %s", name, supportedPlatformClusterProviders())
+	}
+	for _, cp := range s.ClusterProviders {
+		if cp.Name == name {
+			return cp, nil
+		}
+	}
+	return providers.ClusterProviderSetup{}, fmt.Errorf("platform cluster provider %s is not configured as cluster provider", name)
+}
+
+func supportedPlatformClusterProviders() string {
+	names := make([]string, 0, len(platformClusterBuilders))
+	for name := range platformClusterBuilders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (s *OpenMCPSetup) managePlatformCluster(platformClusterName string) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		cp, err := s.platformClusterProvider()
+		if err != nil {
+			return ctx, err
+		}
+		klog.Infof("create platform cluster resource managed by cluster provider %s...", cp.Name)
+		platformCluster := platformClusterBuilders[cp.Name](s.Namespace, s.RunID, platformClusterName)
+		if createErr := c.Client().Resources().Create(ctx, platformCluster); createErr != nil {
+			return ctx, createErr
+		}
+		klog.Info("platform cluster resource created")
+		return ctx, nil
+	}
+}
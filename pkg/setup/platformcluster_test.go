@@ -0,0 +1,67 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+func TestPlatformClusterProvider(t *testing.T) {
+	tests := []struct {
+		name             string
+		clusterProviders []string
+		platformProvider string
+		want             string
+		wantErr          bool
+	}{
+		{
+			name:             "single cluster provider is used implicitly",
+			clusterProviders: []string{"kind"},
+			want:             "kind",
+		},
+		{
+			name:             "explicit cluster provider",
+			clusterProviders: []string{"gardener", "kind"},
+			platformProvider: "kind",
+			want:             "kind",
+		},
+		{
+			name:             "multiple cluster providers without explicit selection",
+			clusterProviders: []string{"gardener", "kind"},
+			wantErr:          true,
+		},
+		{
+			name:             "unsupported cluster provider",
+			clusterProviders: []string{"gardener", "kind"},
+			platformProvider: "gardener",
+			wantErr:          true,
+		},
+		{
+			name:             "selected cluster provider is not configured",
+			clusterProviders: []string{"gardener"},
+			platformProvider: "kind",
+			wantErr:          true,
+		},
+		{
+			name:    "no cluster providers",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &OpenMCPSetup{PlatformClusterProvider: tt.platformProvider}
+			for _, name := range tt.clusterProviders {
+				s.ClusterProviders = append(s.ClusterProviders, providers.ClusterProviderSetup{Name: name})
+			}
+			got, gotErr := s.platformClusterProvider()
+			if tt.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+)
+
+const (
+	defaultMinFreeDisk = "10Gi"
+	// minimum inotify limits to run several kind clusters, see https://kind.sigs.k8s.io/docs/user/known-issues/#pod-errors-due-to-too-many-open-files
+	minInotifyWatches   = 524288
+	minInotifyInstances = 512
+)
+
+// preflightDocker, readSysctl and dockerSocketPath are the docker CLI, the kernel parameters and the docker
+// socket checked by the preflight checks
+var (
+	preflightDocker  = docker
+	readSysctl       = readProcSys
+	dockerSocketPath = dockerSocketHostPath
+)
+
+// PreflightSetup configures the checks that run before any cluster is created
+type PreflightSetup struct {
+	// Disabled skips the preflight checks
+	Disabled bool `json:"disabled,omitempty"`
+	// MinFreeDisk is the free disk space required for the docker data, defaults to 10Gi
+	MinFreeDisk string `json:"minFreeDisk,omitempty"`
+}
+
+// Preflight checks that the docker daemon is reachable, the docker socket that is mounted into the kind nodes
+// exists, the kernel limits suffice for several kind clusters, enough disk space is free and all images
+// of the setup that have to be loaded into the cluster exist locally
+func Preflight(ctx context.Context, s *OpenMCPSetup) error {
+	if _, err := preflightDocker(ctx, nil, "info", "--format", "{{.ServerVersion}}"); err != nil {
+		return fmt.Errorf("docker daemon is not reachable: %w", err)
+	}
+	errs := []error{
+		checkDockerSocket(),
+		checkInotifyLimits(),
+		s.checkFreeDisk(ctx),
+		s.checkLocalImages(ctx),
+	}
+	return errors.Join(errs...)
+}
+
+// preflight runs the preflight checks as setup step
+func (s *OpenMCPSetup) preflight() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if s.Preflight.Disabled {
+			return ctx, nil
+		}
+		klog.Info("run preflight checks...")
+		if err := Preflight(ctx, s); err != nil {
+			return ctx, fmt.Errorf("preflight checks failed: %w", err)
+		}
+		klog.Info("preflight checks passed")
+		return ctx, nil
+	}
+}
+
+// checkDockerSocket checks the docker socket that the kind nodes mount for the cluster provider
+func checkDockerSocket() error {
+	info, err := os.Stat(dockerSocketPath)
+	if err != nil {
+		return fmt.Errorf("docker socket %s is required by the kind cluster provider: %w", dockerSocketPath, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("docker socket %s is required by the kind cluster provider but is not a socket", dockerSocketPath)
+	}
+	return nil
+}
+
+// readProcSys reads the kernel parameter with the passed in name, e.g. fs.inotify.max_user_watches
+func readProcSys(name string) ([]byte, error) {
+	return os.ReadFile("/proc/sys/" + strings.ReplaceAll(name, ".", "/"))
+}
+
+// checkInotifyLimits checks the inotify limits of the kernel. It is skipped on systems without /proc.
+func checkInotifyLimits() error {
+	errs := []error{}
+	limits := []struct {
+		name string
+		min  int
+	}{
+		{name: "fs.inotify.max_user_watches", min: minInotifyWatches},
+		{name: "fs.inotify.max_user_instances", min: minInotifyInstances},
+	}
+	for _, limit := range limits {
+		data, err := readSysctl(limit.name)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			continue
+		}
+		if value < limit.min {
+			errs = append(errs, fmt.Errorf("%s is %d, at least %d is required to run several kind clusters, run: sysctl %s=%d",
+				limit.name, value, limit.min, limit.name, limit.min))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkFreeDisk checks the free disk space of the docker data. If the docker root dir is not accessible,
+// e.g. because docker runs in a VM, the check is skipped.
+func (s *OpenMCPSetup) checkFreeDisk(ctx context.Context) error {
+	minFreeDisk := s.Preflight.MinFreeDisk
+	if minFreeDisk == "" {
+		minFreeDisk = defaultMinFreeDisk
+	}
+	required, err := resource.ParseQuantity(minFreeDisk)
+	if err != nil {
+		return fmt.Errorf("invalid minimum free disk %q: %w", minFreeDisk, err)
+	}
+	dir, err := preflightDocker(ctx, nil, "info", "--format", "{{.DockerRootDir}}")
+	if err != nil {
+		return err
+	}
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		klog.V(2).Infof("skipping free disk check: %v", err)
+		return nil
+	}
+	if free < required.Value() {
+		return fmt.Errorf("%s of free disk space in %s, at least %s is required",
+			resource.NewQuantity(free, resource.BinarySI), dir, required.String())
+	}
+	return nil
+}
+
+// checkLocalImages checks that the images of all components with LoadImageToCluster exist locally.
+// In offline mode, all images are checked when the image archives are prepared.
+func (s *OpenMCPSetup) checkLocalImages(ctx context.Context) error {
+	if s.Offline.Enabled {
+		return nil
+	}
+	errs := []error{}
+	for _, image := range s.localImages() {
+		if _, err := preflightDocker(ctx, nil, "image", "inspect", *image); err != nil {
+			errs = append(errs, fmt.Errorf("image %s has to be loaded into the cluster but does not exist locally", *image))
+		}
+	}
+	return errors.Join(errs...)
+}
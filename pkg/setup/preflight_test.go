@@ -0,0 +1,166 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+// fakeDocker answers docker info with the passed in docker root dir and docker image inspect for the passed in images
+func fakeDocker(rootDir string, images ...string) func(context.Context, io.Reader, ...string) (string, error) {
+	return func(_ context.Context, _ io.Reader, args ...string) (string, error) {
+		switch {
+		case args[0] == "info" && slices.Contains(args, "{{.DockerRootDir}}"):
+			return rootDir, nil
+		case args[0] == "info":
+			return "28.0.0", nil
+		case args[0] == "image" && slices.Contains(images, args[len(args)-1]):
+			return "[]", nil
+		}
+		return "", errors.New("docker " + strings.Join(args, " ") + " failed")
+	}
+}
+
+func TestCheckInotifyLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  map[string]string
+		wantErr string
+	}{
+		{
+			name: "limits suffice",
+			limits: map[string]string{
+				"fs.inotify.max_user_watches":   "1048576\n",
+				"fs.inotify.max_user_instances": "512\n",
+			},
+		},
+		{
+			name: "limits too low",
+			limits: map[string]string{
+				"fs.inotify.max_user_watches":   "8192\n",
+				"fs.inotify.max_user_instances": "128\n",
+			},
+			wantErr: "fs.inotify.max_user_watches is 8192, at least 524288 is required to run several kind clusters, run: sysctl fs.inotify.max_user_watches=524288\n" +
+				"fs.inotify.max_user_instances is 128, at least 512 is required to run several kind clusters, run: sysctl fs.inotify.max_user_instances=512",
+		},
+		{
+			name: "unreadable and invalid limits are skipped",
+			limits: map[string]string{
+				"fs.inotify.max_user_instances": "unlimited\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readSysctl = func(name string) ([]byte, error) {
+				value, ok := tt.limits[name]
+				if !ok {
+					return nil, os.ErrNotExist
+				}
+				return []byte(value), nil
+			}
+			defer func() { readSysctl = readProcSys }()
+			err := checkInotifyLimits()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckLocalImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   *OpenMCPSetup
+		wantErr string
+	}{
+		{
+			name: "images exist locally",
+			setup: &OpenMCPSetup{
+				Operator: OpenMCPOperatorSetup{Image: "openmcp-operator:dev", LoadImageToCluster: true},
+			},
+		},
+		{
+			name: "only images loaded into the cluster are checked",
+			setup: &OpenMCPSetup{
+				Operator: OpenMCPOperatorSetup{Image: "ghcr.io/openmcp-project/openmcp-operator:v0.1.0"},
+			},
+		},
+		{
+			name: "missing images",
+			setup: &OpenMCPSetup{
+				Operator: OpenMCPOperatorSetup{Image: "openmcp-operator:dev", LoadImageToCluster: true},
+				ClusterProviders: []providers.ClusterProviderSetup{
+					{Name: "kind", Image: "cluster-provider-kind:dev", LoadImageToCluster: true},
+					{Name: "gardener", Image: "cluster-provider-gardener:dev", LoadImageToCluster: true},
+				},
+			},
+			wantErr: "image cluster-provider-kind:dev has to be loaded into the cluster but does not exist locally\n" +
+				"image cluster-provider-gardener:dev has to be loaded into the cluster but does not exist locally",
+		},
+		{
+			name: "offline mode checks the images when preparing the archives",
+			setup: &OpenMCPSetup{
+				Operator: OpenMCPOperatorSetup{Image: "cluster-provider-kind:dev", LoadImageToCluster: true},
+				Offline:  OfflineSetup{Enabled: true},
+			},
+		},
+	}
+	preflightDocker = fakeDocker("", "openmcp-operator:dev")
+	defer func() { preflightDocker = docker }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.setup.checkLocalImages(context.Background())
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	notASocket := filepath.Join(t.TempDir(), "docker.sock")
+	require.NoError(t, os.WriteFile(notASocket, nil, 0o644))
+	dockerSocketPath = notASocket
+	readSysctl = func(name string) ([]byte, error) {
+		if name == "fs.inotify.max_user_instances" {
+			return []byte("128"), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	defer func() {
+		preflightDocker, readSysctl, dockerSocketPath = docker, readProcSys, dockerSocketHostPath
+	}()
+	s := &OpenMCPSetup{
+		Operator:  OpenMCPOperatorSetup{Image: "openmcp-operator:dev", LoadImageToCluster: true},
+		Preflight: PreflightSetup{MinFreeDisk: "1Ki"},
+	}
+
+	preflightDocker = func(context.Context, io.Reader, ...string) (string, error) {
+		return "", errors.New("cannot connect to the docker daemon")
+	}
+	assert.EqualError(t, Preflight(context.Background(), s), "docker daemon is not reachable: cannot connect to the docker daemon")
+
+	preflightDocker = fakeDocker(t.TempDir())
+	err := Preflight(context.Background(), s)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"docker socket " + notASocket + " is required by the kind cluster provider but is not a socket",
+		"fs.inotify.max_user_instances is 128, at least 512 is required to run several kind clusters, run: sysctl fs.inotify.max_user_instances=512",
+		"image openmcp-operator:dev has to be loaded into the cluster but does not exist locally",
+	}, strings.Split(err.Error(), "\n"))
+}
@@ -0,0 +1,188 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/internal"
+)
+
+const (
+	defaultRegistryName     = "openmcp-registry"
+	defaultRegistryImage    = "registry:2"
+	defaultRegistryHostPort = 5001
+	kindNetwork             = "kind"
+)
+
+// RegistrySetup configures a local OCI registry that is available to all kind clusters of a run.
+// Local images are pushed to the registry instead of being loaded into the platform cluster, so that
+// they can also be pulled in clusters created by the cluster provider. The registry container is kept
+// after the run and shared by all runs on the docker host.
+// The registry is configured as a containerd mirror on every node, which requires a kind node image
+// that reads registry hosts from /etc/containerd/certs.d.
+type RegistrySetup struct {
+	// Enabled starts the registry
+	Enabled bool `json:"enabled,omitempty"`
+	// Name is the name of the registry container, defaults to openmcp-registry
+	Name string `json:"name,omitempty"`
+	// Image is the registry image, defaults to registry:2
+	Image string `json:"image,omitempty"`
+	// HostPort is the port the registry is published on at localhost, defaults to 5001
+	HostPort int `json:"hostPort,omitempty"`
+}
+
+func (r RegistrySetup) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return defaultRegistryName
+}
+
+func (r RegistrySetup) image() string {
+	if r.Image != "" {
+		return r.Image
+	}
+	return defaultRegistryImage
+}
+
+// Address returns the address images are pushed to and pulled from
+func (r RegistrySetup) Address() string {
+	port := r.HostPort
+	if port == 0 {
+		port = defaultRegistryHostPort
+	}
+	return "localhost:" + strconv.Itoa(port)
+}
+
+// RegistryImage returns the reference of an image in the registry, e.g. localhost:5001/openmcp-project/images/foo:v1
+// for ghcr.io/openmcp-project/images/foo:v1
+func (r RegistrySetup) RegistryImage(image string) string {
+	if i := strings.Index(image, "/"); i >= 0 {
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			image = image[i+1:]
+		}
+	}
+	return r.Address() + "/" + image
+}
+
+// hostsConfig returns the containerd hosts config that redirects pulls from the registry address
+// to the registry container on the kind network
+func (r RegistrySetup) hostsConfig() string {
+	return fmt.Sprintf("[host.\"http://%s:5000\"]\n", r.name())
+}
+
+// registry runs the local registry and configures it as mirror on all kind clusters of a run
+type registry struct {
+	setup   RegistrySetup
+	watcher *nodeWatcher
+	mu      sync.Mutex
+}
+
+func newRegistry(setup RegistrySetup, watcher *nodeWatcher) *registry {
+	return &registry{
+		setup:   setup,
+		watcher: watcher,
+	}
+}
+
+// start starts the registry container unless it is running already
+func (r *registry) start() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if !r.setup.Enabled {
+			return ctx, nil
+		}
+		name := r.setup.name()
+		running, err := docker(ctx, nil, "inspect", "-f", "{{.State.Running}}", name)
+		if err == nil && running == "true" {
+			klog.Infof("using running registry %s at %s", name, r.setup.Address())
+			return ctx, nil
+		}
+		if err == nil {
+			_, err = docker(ctx, nil, "start", name)
+			return ctx, err
+		}
+		klog.Infof("start registry %s at %s", name, r.setup.Address())
+		port := strings.TrimPrefix(r.setup.Address(), "localhost:")
+		_, err = docker(ctx, nil, "run", "-d", "--restart=always", "-p", "127.0.0.1:"+port+":5000", "--name", name, r.setup.image())
+		return ctx, err
+	}
+}
+
+// attach connects the registry to the kind network and configures the mirror on every node of the run
+func (r *registry) attach() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if !r.setup.Enabled {
+			return ctx, nil
+		}
+		name := r.setup.name()
+		networks, err := docker(ctx, nil, "inspect", "-f", "{{json .NetworkSettings.Networks}}", name)
+		if err != nil {
+			return ctx, err
+		}
+		if !strings.Contains(networks, `"`+kindNetwork+`"`) {
+			if _, err := docker(ctx, nil, "network", "connect", kindNetwork, name); err != nil {
+				return ctx, err
+			}
+		}
+		r.watcher.register("configure registry mirror", r.configureNode)
+		return ctx, nil
+	}
+}
+
+// configureNode writes the containerd hosts config of the registry to the node
+func (r *registry) configureNode(ctx context.Context, node string) error {
+	dir := "/etc/containerd/certs.d/" + r.setup.Address()
+	script := fmt.Sprintf("mkdir -p %q && cat > %q", dir, dir+"/hosts.toml")
+	if _, err := docker(ctx, strings.NewReader(r.setup.hostsConfig()), "exec", "-i", node, "sh", "-c", script); err != nil {
+		return err
+	}
+	klog.Infof("configured registry mirror on node %s", node)
+	return nil
+}
+
+// push pushes the passed in local images to the registry and rewrites them to the registry address
+func (r *registry) push(ctx context.Context, limit int, images []*string) error {
+	refs := map[string][]*string{}
+	for _, image := range images {
+		refs[*image] = append(refs[*image], image)
+	}
+	pushes := []func() error{}
+	for image, pointers := range refs {
+		pushes = append(pushes, func() error {
+			target := r.setup.RegistryImage(image)
+			klog.Infof("push image %s to %s", image, target)
+			if _, err := docker(ctx, nil, "tag", image, target); err != nil {
+				return err
+			}
+			if _, err := docker(ctx, nil, "push", target); err != nil {
+				return err
+			}
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			for _, p := range pointers {
+				*p = target
+			}
+			return nil
+		})
+	}
+	return internal.RunConcurrently(limit, pushes...)
+}
+
+// reuseImages pushes the local images of a reused environment to the registry again, so that the images
+// of the components match the installed ones
+func (s *OpenMCPSetup) reuseImages(reg *registry) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if !s.Registry.Enabled {
+			return ctx, nil
+		}
+		return ctx, reg.push(ctx, s.Concurrency, s.localImages())
+	}
+}
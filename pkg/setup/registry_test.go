@@ -0,0 +1,43 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry RegistrySetup
+		image    string
+		want     string
+	}{
+		{
+			name:  "registry host is replaced",
+			image: "ghcr.io/openmcp-project/images/openmcp-operator:v1.0.0",
+			want:  "localhost:5001/openmcp-project/images/openmcp-operator:v1.0.0",
+		},
+		{
+			name:  "registry host with port is replaced",
+			image: "localhost:5000/crossplane:dev",
+			want:  "localhost:5001/crossplane:dev",
+		},
+		{
+			name:  "docker hub image keeps its path",
+			image: "library/busybox:1.37",
+			want:  "localhost:5001/library/busybox:1.37",
+		},
+		{
+			name:     "custom host port",
+			registry: RegistrySetup{HostPort: 5555},
+			image:    "kind:dev",
+			want:     "localhost:5555/kind:dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.registry.RegistryImage(tt.image))
+		})
+	}
+}
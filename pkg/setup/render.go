@@ -0,0 +1,203 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/openmcp-testing/internal"
+	"github.com/openmcp-project/openmcp-testing/pkg/platformservices"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+// dryRunID is the run ID of rendered manifests if no run ID is configured
+const dryRunID = "dryrun"
+
+// Manifest is a rendered manifest that Bootstrap applies to the platform cluster
+type Manifest struct {
+	// Phase is the phase of Bootstrap the manifest is applied in
+	Phase Phase
+	// Name identifies the component of the manifest
+	Name string
+	// Content is the manifest as multi-document YAML
+	Content string
+}
+
+// FileName returns the name of the manifest file, prefixed with the position of the manifest in the install order
+func (m Manifest) FileName(index int) string {
+	return fmt.Sprintf("%02d-%s-%s.yaml", index, m.Phase, m.Name)
+}
+
+// RenderManifests renders the manifests of the environment in install order without creating any cluster.
+// Image overrides of the environment are applied. The run ID defaults to dryrun if none is configured.
+// The setup itself is not modified.
+func (s *OpenMCPSetup) RenderManifests(ctx context.Context) ([]Manifest, error) {
+	r := s.clone()
+	runID, err := r.configuredRunID()
+	if err != nil {
+		return nil, err
+	}
+	if runID == "" {
+		runID = dryRunID
+	}
+	r.RunID = runID
+	r.Operator.Namespace = r.Namespace
+	if _, err := r.applyOverrides()(ctx, nil); err != nil {
+		return nil, err
+	}
+	platformProvider, err := r.platformClusterProvider()
+	if err != nil {
+		return nil, err
+	}
+	sorted, err := extensions.Sort(r.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	manifests := []Manifest{{
+		Phase:   PhaseClusterCreation,
+		Name:    "namespace",
+		Content: fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", r.Namespace),
+	}}
+	// add renders a manifest unless a previous manifest failed, the first error is returned at the end
+	add := func(phase Phase, name string, render func() (string, error)) {
+		if err != nil {
+			return
+		}
+		var content string
+		if content, err = render(); err != nil {
+			err = fmt.Errorf("render %s %s: %w", phase, name, err)
+			return
+		}
+		manifests = append(manifests, Manifest{Phase: phase, Name: name, Content: strings.TrimSpace(content) + "\n"})
+	}
+	add(PhaseOperator, r.Operator.Name, r.renderOperator)
+	for _, cp := range r.ClusterProviders {
+		add(PhaseClusterProviders, cp.Name, func() (string, error) {
+			return providers.RenderClusterProvider(cp)
+		})
+	}
+	add(PhasePlatformCluster, "platform", func() (string, error) {
+		cluster := platformClusterBuilders[platformProvider.Name](r.Namespace, r.RunID, platformClusterName(r.RunID))
+		data, err := yaml.Marshal(cluster.Object)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	})
+	for _, ext := range sorted {
+		add(PhaseExtensions, ext.Name(), func() (string, error) {
+			return renderExtension(ctx, ext)
+		})
+	}
+	for _, ps := range r.PlatformServices {
+		add(PhasePlatformServices, ps.Name, func() (string, error) {
+			return platformservices.RenderPlatformService(ps)
+		})
+		if ps.PlatformServiceConfigsDir != "" {
+			add(PhasePlatformServices, ps.Name+"-configs", func() (string, error) {
+				return renderDir(ps.PlatformServiceConfigsDir)
+			})
+		}
+	}
+	for _, sp := range r.ServiceProviders {
+		add(PhaseServiceProviders, sp.Name, func() (string, error) {
+			return providers.RenderServiceProvider(sp)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// DryRun writes the manifests of the environment in install order to dir without creating any cluster,
+// see RenderManifests. YAML files that already exist in dir are removed first, so that dir only contains
+// the manifests of the setup and can be compared with golden files.
+func (s *OpenMCPSetup) DryRun(ctx context.Context, dir string) error {
+	manifests, err := s.RenderManifests(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, file := range existing {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	for i, m := range manifests {
+		file := filepath.Join(dir, m.FileName(i))
+		if err := os.WriteFile(file, []byte(m.Content), 0o644); err != nil {
+			return err
+		}
+		klog.Infof("rendered %s", file)
+	}
+	return nil
+}
+
+// renderOperator renders the operator template with the configuration of the run
+func (s *OpenMCPSetup) renderOperator() (string, error) {
+	tmpl, err := configFS.ReadFile("config/operator.yaml.tmpl")
+	if err != nil {
+		return "", err
+	}
+	data, err := s.Operator.templateData(s.RunID)
+	if err != nil {
+		return "", err
+	}
+	return internal.ExecTemplate(string(tmpl), data)
+}
+
+// renderExtension renders the manifests of extensions that implement extensions.Renderer. Other extensions
+// are rendered as a comment.
+func renderExtension(ctx context.Context, ext extensions.Extension) (string, error) {
+	renderer, ok := ext.(extensions.Renderer)
+	if !ok {
+		klog.Warningf("extension %s does not support rendering its manifests", ext.Name())
+		return fmt.Sprintf("# extension %s does not support rendering its manifests\n", ext.Name()), nil
+	}
+	return renderer.Render(ctx)
+}
+
+// renderDir concatenates the files of a directory that is imported into a cluster in the order they are applied
+func renderDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	docs := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, strings.TrimPrefix(strings.TrimSpace(string(data)), "---\n")+"\n")
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// clone returns a copy of the setup whose components can be modified without modifying the setup
+func (s *OpenMCPSetup) clone() *OpenMCPSetup {
+	c := *s
+	c.ClusterProviders = slices.Clone(s.ClusterProviders)
+	c.ServiceProviders = slices.Clone(s.ServiceProviders)
+	c.PlatformServices = slices.Clone(s.PlatformServices)
+	c.timings = nil
+	c.installed = nil
+	return &c
+}
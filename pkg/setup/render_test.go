@@ -0,0 +1,154 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+	"github.com/openmcp-project/openmcp-testing/pkg/setup/extensions"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestDryRunGolden(t *testing.T) {
+	s, err := LoadOpenMCPSetup("testdata/render/setup.yaml")
+	require.NoError(t, err)
+	golden := filepath.Join("testdata", "render", "golden")
+	if *update {
+		require.NoError(t, s.DryRun(context.Background(), golden))
+	}
+	dir := t.TempDir()
+	require.NoError(t, s.DryRun(context.Background(), dir))
+
+	want, err := filepath.Glob(filepath.Join(golden, "*.yaml"))
+	require.NoError(t, err)
+	got, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, baseNames(want), baseNames(got), "rendered files differ, run go test with -update")
+	for _, file := range want {
+		wantData, err := os.ReadFile(file)
+		require.NoError(t, err)
+		gotData, err := os.ReadFile(filepath.Join(dir, filepath.Base(file)))
+		require.NoError(t, err)
+		assert.Equal(t, string(wantData), string(gotData), "%s differs, run go test with -update", filepath.Base(file))
+	}
+}
+
+func TestRenderManifests(t *testing.T) {
+	t.Setenv(ImageEnvVar("crossplane"), "crossplane:dev")
+	s := &OpenMCPSetup{
+		Namespace: "openmcp-system",
+		Operator:  OpenMCPOperatorSetup{Name: "openmcp-operator", Image: "operator:v1"},
+		ClusterProviders: []providers.ClusterProviderSetup{
+			{Name: "kind", Image: "kind:v1"},
+		},
+		ServiceProviders: []providers.ServiceProviderSetup{
+			{Name: "crossplane", Image: "crossplane:v1"},
+		},
+		Extensions: []extensions.Extension{
+			&renderedExtension{},
+			&plainExtension{},
+		},
+	}
+	manifests, err := s.RenderManifests(context.Background())
+	require.NoError(t, err)
+	names := []string{}
+	for i, m := range manifests {
+		names = append(names, m.FileName(i))
+	}
+	assert.Equal(t, []string{
+		"00-cluster-creation-namespace.yaml",
+		"01-operator-openmcp-operator.yaml",
+		"02-cluster-providers-kind.yaml",
+		"03-platform-cluster-platform.yaml",
+		"04-extensions-rendered.yaml",
+		"05-extensions-plain.yaml",
+		"06-service-providers-crossplane.yaml",
+	}, names)
+	assert.Contains(t, manifests[1].Content, clusterutils.RunIDLabel+": "+dryRunID)
+	assert.Equal(t, "kind: ConfigMap\n", manifests[4].Content)
+	assert.Contains(t, manifests[5].Content, "does not support rendering")
+	assert.Contains(t, manifests[6].Content, "image: crossplane:dev")
+	// the setup is not modified
+	assert.Equal(t, "crossplane:v1", s.ServiceProviders[0].Image)
+	assert.Empty(t, s.RunID)
+}
+
+func TestRenderManifestsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *OpenMCPSetup)
+		wantErr string
+	}{
+		{
+			name:    "operator",
+			modify:  func(s *OpenMCPSetup) { s.Operator.Verbosity = "TRACE" },
+			wantErr: "render operator openmcp-operator:",
+		},
+		{
+			name: "extension",
+			modify: func(s *OpenMCPSetup) {
+				s.Extensions = []extensions.Extension{&failingExtension{}}
+			},
+			wantErr: "render extensions failing: boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &OpenMCPSetup{
+				Namespace: "openmcp-system",
+				Operator:  OpenMCPOperatorSetup{Name: "openmcp-operator", Image: "operator:v1"},
+				ClusterProviders: []providers.ClusterProviderSetup{
+					{Name: "kind", Image: "kind:v1"},
+				},
+			}
+			tt.modify(s)
+			manifests, err := s.RenderManifests(context.Background())
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, manifests)
+			assert.Error(t, s.DryRun(context.Background(), t.TempDir()))
+		})
+	}
+}
+
+func baseNames(files []string) []string {
+	names := []string{}
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	return names
+}
+
+type plainExtension struct{}
+
+func (e *plainExtension) Name() string { return "plain" }
+
+func (e *plainExtension) Install(context.Context, *envconf.Config) error { return nil }
+
+func (e *plainExtension) RegisterSchemes(context.Context, *runtime.Scheme) error { return nil }
+
+type renderedExtension struct {
+	plainExtension
+}
+
+func (e *renderedExtension) Name() string { return "rendered" }
+
+func (e *renderedExtension) Render(context.Context) (string, error) { return "kind: ConfigMap\n", nil }
+
+type failingExtension struct {
+	plainExtension
+}
+
+func (e *failingExtension) Name() string { return "failing" }
+
+func (e *failingExtension) Render(context.Context) (string, error) { return "", errors.New("boom") }
@@ -0,0 +1,224 @@
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/diagnostics"
+)
+
+// ReportFileEnvVar sets the file the bootstrap report is written to if OpenMCPSetup.ReportFile is not set
+const ReportFileEnvVar = "OPENMCP_REPORT_FILE"
+
+// reportFileName is the name of the bootstrap report in the artifacts directory
+const reportFileName = "bootstrap-report.json"
+
+// BootstrapReport contains the durations of the phases of Bootstrap and of the installations of the components
+type BootstrapReport struct {
+	RunID     string    `json:"runId"`
+	StartedAt time.Time `json:"startedAt"`
+	// Duration is the time from the start of the first phase to the end of the last phase
+	Duration time.Duration `json:"-"`
+	// DurationSeconds is Duration in seconds
+	DurationSeconds float64 `json:"durationSeconds"`
+	// Failed is set if a phase failed
+	Failed bool          `json:"failed,omitempty"`
+	Phases []PhaseTiming `json:"phases"`
+}
+
+// PhaseTiming is the duration of a phase of Bootstrap
+type PhaseTiming struct {
+	Phase           Phase         `json:"phase"`
+	StartedAt       time.Time     `json:"startedAt"`
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Failed          bool          `json:"failed,omitempty"`
+	// Components are the components installed during the phase
+	Components []ComponentTiming `json:"components,omitempty"`
+}
+
+// ComponentTiming is the duration of the installation of a component, from the creation of its resources
+// until it is ready
+type ComponentTiming struct {
+	Kind            string        `json:"kind"`
+	Name            string        `json:"name"`
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Failed          bool          `json:"failed,omitempty"`
+}
+
+// timings records the durations of the phases and components of a run
+type timings struct {
+	mu         sync.Mutex
+	runID      string
+	phases     []PhaseTiming
+	components map[Phase][]ComponentTiming
+	reported   bool
+}
+
+func newTimings(runID string) *timings {
+	return &timings{
+		runID:      runID,
+		components: map[Phase][]ComponentTiming{},
+	}
+}
+
+// phase records the duration of a phase
+func (t *timings) phase(phase Phase, start time.Time, failed bool) {
+	if t == nil {
+		return
+	}
+	d := time.Since(start)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases = append(t.phases, PhaseTiming{
+		Phase:           phase,
+		StartedAt:       start,
+		Duration:        d,
+		DurationSeconds: d.Seconds(),
+		Failed:          failed,
+	})
+}
+
+// component runs the installation of a component and records its duration
+func (t *timings) component(phase Phase, kind string, name string, install func() error) error {
+	start := time.Now()
+	err := install()
+	if t == nil {
+		return err
+	}
+	d := time.Since(start)
+	if err != nil {
+		klog.Infof("%s %s failed after %s", kind, name, d.Round(time.Millisecond))
+	} else {
+		klog.Infof("%s %s ready after %s", kind, name, d.Round(time.Millisecond))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.components[phase] = append(t.components[phase], ComponentTiming{
+		Kind:            kind,
+		Name:            name,
+		Duration:        d,
+		DurationSeconds: d.Seconds(),
+		Failed:          err != nil,
+	})
+	return err
+}
+
+// report returns the report of all phases recorded so far
+func (t *timings) report() BootstrapReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := BootstrapReport{RunID: t.runID, Phases: []PhaseTiming{}}
+	for _, p := range t.phases {
+		p.Components = append([]ComponentTiming{}, t.components[p.Phase]...)
+		if r.StartedAt.IsZero() {
+			r.StartedAt = p.StartedAt
+		}
+		r.Duration = p.StartedAt.Add(p.Duration).Sub(r.StartedAt)
+		r.Failed = r.Failed || p.Failed
+		r.Phases = append(r.Phases, p)
+	}
+	r.DurationSeconds = r.Duration.Seconds()
+	return r
+}
+
+// Report returns the durations of the phases of Bootstrap that have run so far
+func (e *OpenMCPEnvironment) Report() BootstrapReport {
+	if e.setup.timings == nil {
+		return BootstrapReport{RunID: e.RunID, Phases: []PhaseTiming{}}
+	}
+	return e.setup.timings.report()
+}
+
+// reportFile returns the file the bootstrap report is written to. An empty result means that only
+// the summary table is logged.
+func (s *OpenMCPSetup) reportFile() string {
+	if s.ReportFile != "" {
+		return s.ReportFile
+	}
+	if file := os.Getenv(ReportFileEnvVar); file != "" {
+		return file
+	}
+	if dir := diagnostics.ArtifactsDir(s.ArtifactsDir); dir != "" {
+		return filepath.Join(dir, reportFileName)
+	}
+	return ""
+}
+
+// writeReport logs the summary table and writes the bootstrap report once, either after the last phase
+// or, if a phase failed, during the teardown. Failures to write the report don't fail the run.
+func (e *OpenMCPEnvironment) writeReport() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if err := e.reportTimings(); err != nil {
+			klog.Errorf("failed to write bootstrap report: %v", err)
+		}
+		return ctx, nil
+	}
+}
+
+func (e *OpenMCPEnvironment) reportTimings() error {
+	t := e.setup.timings
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	reported := t.reported
+	t.reported = true
+	t.mu.Unlock()
+	if reported {
+		return nil
+	}
+	r := t.report()
+	klog.Infof("bootstrap of run %s took %s:\n%s", r.RunID, r.Duration.Round(time.Millisecond), r.Table())
+	file := e.setup.reportFile()
+	if file == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return err
+	}
+	klog.Infof("bootstrap report written to %s", file)
+	return nil
+}
+
+// Table returns the durations of the report as a table with one row per phase and component
+func (r BootstrapReport) Table() string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PHASE\tCOMPONENT\tDURATION\tSTATUS")
+	for _, p := range r.Phases {
+		fmt.Fprintf(w, "%s\t\t%s\t%s\n", p.Phase, p.Duration.Round(time.Millisecond), timingStatus(p.Failed))
+		for _, c := range p.Components {
+			fmt.Fprintf(w, "\t%s %s\t%s\t%s\n", c.Kind, c.Name, c.Duration.Round(time.Millisecond), timingStatus(c.Failed))
+		}
+	}
+	fmt.Fprintf(w, "total\t\t%s\t%s\n", r.Duration.Round(time.Millisecond), timingStatus(r.Failed))
+	w.Flush()
+	return sb.String()
+}
+
+func timingStatus(failed bool) string {
+	if failed {
+		return "failed"
+	}
+	return "ok"
+}
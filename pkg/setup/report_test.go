@@ -0,0 +1,64 @@
+package setup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingsReport(t *testing.T) {
+	timings := newTimings("abc")
+	start := time.Now().Add(-3 * time.Second)
+	timings.phase(PhaseOperator, start, false)
+	err := timings.component(PhaseClusterProviders, "ClusterProvider", "kind", func() error { return nil })
+	require.NoError(t, err)
+	err = timings.component(PhaseClusterProviders, "ClusterProvider", "gardener", func() error { return errors.New("not ready") })
+	assert.Error(t, err)
+	timings.phase(PhaseClusterProviders, time.Now(), true)
+
+	r := timings.report()
+	assert.Equal(t, "abc", r.RunID)
+	assert.True(t, r.Failed)
+	assert.Equal(t, start, r.StartedAt)
+	assert.GreaterOrEqual(t, r.Duration, 3*time.Second)
+	require.Len(t, r.Phases, 2)
+	assert.Equal(t, PhaseOperator, r.Phases[0].Phase)
+	assert.Empty(t, r.Phases[0].Components)
+	assert.Equal(t, PhaseClusterProviders, r.Phases[1].Phase)
+	require.Len(t, r.Phases[1].Components, 2)
+	assert.Equal(t, "kind", r.Phases[1].Components[0].Name)
+	assert.False(t, r.Phases[1].Components[0].Failed)
+	assert.True(t, r.Phases[1].Components[1].Failed)
+
+	table := r.Table()
+	assert.Contains(t, table, "PHASE")
+	assert.Contains(t, table, "ClusterProvider gardener")
+	assert.Contains(t, table, "failed")
+}
+
+func TestReportTimings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report", "bootstrap.json")
+	s := &OpenMCPSetup{RunID: "abc", ReportFile: file}
+	e := s.newEnvironment("platform-abc")
+	s.timings.phase(PhaseClusterCreation, time.Now(), false)
+	require.NoError(t, e.reportTimings())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	r := BootstrapReport{}
+	require.NoError(t, json.Unmarshal(data, &r))
+	assert.Equal(t, "abc", r.RunID)
+	require.Len(t, r.Phases, 1)
+	assert.Equal(t, PhaseClusterCreation, r.Phases[0].Phase)
+
+	// the report is only written once
+	require.NoError(t, os.Remove(file))
+	require.NoError(t, e.reportTimings())
+	assert.NoFileExists(t, file)
+}
@@ -0,0 +1,136 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/platformservices"
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+)
+
+// ReuseEnvVar enables the reuse mode when set to a true value, see OpenMCPSetup.Reuse
+const ReuseEnvVar = "OPENMCP_REUSE"
+
+// platformClusterPrefix is the name prefix of every platform kind cluster created by Bootstrap
+const platformClusterPrefix = "platform"
+
+// onboardingClusterPrefix is the name prefix of the onboarding kind cluster the scheduler creates for a run
+const onboardingClusterPrefix = "onboarding"
+
+// reuseEnabled returns true if reuse has been enabled either by field or by environment variable
+func (s *OpenMCPSetup) reuseEnabled() bool {
+	return s.Reuse || boolFromEnv(ReuseEnvVar)
+}
+
+// boolFromEnv returns true if the environment variable with the passed in name is set to a true value
+func boolFromEnv(name string) bool {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		klog.Warningf("ignoring invalid value %q of %s: %v", value, name, err)
+		return false
+	}
+	return enabled
+}
+
+// findReusablePlatformCluster returns the name of the platform kind cluster to reuse. With a run ID, only the
+// platform cluster of that run is reused and only if the run has been kept or its process is not alive anymore.
+// Without a run ID, only platform clusters of runs that have been recorded as kept in the state directory are
+// reused. If several runs have been kept, a run ID is required.
+func findReusablePlatformCluster(runID string, stateDir string, alive func(RunState) bool) (string, bool, error) {
+	clusters, err := listRunClusters("")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+	states, err := readRunStates(stateDir)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read run states: %w", err)
+	}
+	candidates := []string{}
+	if runID != "" {
+		for _, state := range states {
+			if state.RunID == runID && !state.Keep && alive(state) {
+				return "", false, fmt.Errorf("platform cluster %s is in use by run %s of process %d on %s",
+					platformClusterName(runID), runID, state.PID, state.Hostname)
+			}
+		}
+		candidates = append(candidates, platformClusterName(runID))
+	} else {
+		for _, state := range states {
+			if state.Keep {
+				candidates = append(candidates, platformClusterName(state.RunID))
+			}
+		}
+	}
+	candidates = slices.DeleteFunc(candidates, func(name string) bool { return !slices.Contains(clusters, name) })
+	switch len(candidates) {
+	case 0:
+		klog.Info("no reusable platform cluster found")
+		return "", false, nil
+	case 1:
+		return candidates[0], true, nil
+	}
+	return "", false, fmt.Errorf("several reusable platform clusters found: %s, set %s to select one",
+		strings.Join(candidates, ", "), RunIDEnvVar)
+}
+
+// verifyReusedEnvironment checks that every component of the setup is already installed and ready
+func (s *OpenMCPSetup) verifyReusedEnvironment() env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		klog.Info("verify reused environment...")
+		if err := s.verifyOpenMCPOperator(ctx, c); err != nil {
+			return ctx, fmt.Errorf("reused environment: %w", err)
+		}
+		for _, cp := range s.ClusterProviders {
+			if err := providers.VerifyClusterProvider(ctx, c, cp); err != nil {
+				return ctx, fmt.Errorf("reused environment: %w", err)
+			}
+		}
+		if err := providers.ClustersReady(ctx, c, s.WaitOpts...); err != nil {
+			return ctx, fmt.Errorf("reused environment: %w", err)
+		}
+		for _, ps := range s.PlatformServices {
+			if err := platformservices.VerifyPlatformService(ctx, c, ps); err != nil {
+				return ctx, fmt.Errorf("reused environment: %w", err)
+			}
+		}
+		for _, sp := range s.ServiceProviders {
+			if err := providers.VerifyServiceProvider(ctx, c, sp); err != nil {
+				return ctx, fmt.Errorf("reused environment: %w", err)
+			}
+		}
+		klog.Info("reused environment ready")
+		return ctx, nil
+	}
+}
+
+func (s *OpenMCPSetup) verifyOpenMCPOperator(ctx context.Context, c *envconf.Config) error {
+	if err := wait.For(conditions.New(c.Client().Resources()).
+		DeploymentAvailable(s.Operator.Name, s.Operator.Namespace), s.Operator.WaitOpts...); err != nil {
+		return err
+	}
+	deployment := &appsv1.Deployment{}
+	if err := c.Client().Resources().Get(ctx, s.Operator.Name, s.Operator.Namespace, deployment); err != nil {
+		return err
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Image != s.Operator.Image {
+			return fmt.Errorf("deployment %s: expected image %s, found %s", s.Operator.Name, s.Operator.Image, container.Image)
+		}
+	}
+	return nil
+}
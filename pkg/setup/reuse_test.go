@@ -0,0 +1,93 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+func TestFindReusablePlatformCluster(t *testing.T) {
+	tests := []struct {
+		name     string
+		runID    string
+		states   []RunState
+		clusters []string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "platform cluster of the run",
+			runID:    "abc",
+			clusters: []string{"platform-ab", "platform-abc", "workload-abc"},
+			want:     "platform-abc",
+		},
+		{
+			name:     "platform cluster of a kept run",
+			runID:    "abc",
+			states:   []RunState{{RunID: "abc", PID: 1, Keep: true}},
+			clusters: []string{"platform-abc"},
+			want:     "platform-abc",
+		},
+		{
+			name:     "platform cluster of a run whose process is gone",
+			runID:    "abc",
+			states:   []RunState{{RunID: "abc", PID: 2}},
+			clusters: []string{"platform-abc"},
+			want:     "platform-abc",
+		},
+		{
+			name:     "platform cluster of a live run",
+			runID:    "abc",
+			states:   []RunState{{RunID: "abc", PID: 1, Hostname: "host"}},
+			clusters: []string{"platform-abc"},
+			wantErr:  "platform cluster platform-abc is in use by run abc of process 1 on host",
+		},
+		{
+			name:     "platform cluster of the run doesn't exist",
+			runID:    "abc",
+			clusters: []string{"platform-def"},
+		},
+		{
+			name:     "only platform cluster of a kept run",
+			states:   []RunState{{RunID: "abc"}, {RunID: "def", Keep: true}},
+			clusters: []string{"platform-abc", "platform-def"},
+			want:     "platform-def",
+		},
+		{
+			name:     "platform clusters of unknown runs are not reused",
+			clusters: []string{"platform-abc"},
+		},
+		{
+			name:     "kept run without platform cluster",
+			states:   []RunState{{RunID: "abc", Keep: true}},
+			clusters: []string{"onboarding-abc"},
+		},
+		{
+			name:     "several kept runs",
+			states:   []RunState{{RunID: "abc", Keep: true}, {RunID: "def", Keep: true}},
+			clusters: []string{"platform-abc", "platform-def"},
+			wantErr:  "several reusable platform clusters found: platform-abc, platform-def, set OPENMCP_RUN_ID to select one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, state := range tt.states {
+				require.NoError(t, writeRunState(dir, state))
+			}
+			listRunClusters = func(string) ([]string, error) { return tt.clusters, nil }
+			defer func() { listRunClusters = clusterutils.RunClusterNames }()
+			name, found, err := findReusablePlatformCluster(tt.runID, dir, func(state RunState) bool { return state.PID == 1 })
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want != "", found)
+			assert.Equal(t, tt.want, name)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+// RunIDEnvVar sets the run ID if OpenMCPSetup.RunID is empty
+const RunIDEnvVar = "OPENMCP_RUN_ID"
+
+var runIDPattern = regexp.MustCompile(`^[a-z0-9]{1,16}$`)
+
+// configuredRunID returns the run ID configured by field or environment variable or an empty string
+func (s *OpenMCPSetup) configuredRunID() (string, error) {
+	id := s.RunID
+	if id == "" {
+		id = os.Getenv(RunIDEnvVar)
+	}
+	if id == "" {
+		return "", nil
+	}
+	if !runIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid run ID %q: must consist of 1 to 16 lowercase alphanumeric characters", id)
+	}
+	return id, nil
+}
+
+func newRunID() string {
+	return envconf.RandomName("", 8)
+}
+
+// platformClusterName returns the name of the platform kind cluster of a run
+func platformClusterName(runID string) string {
+	return platformClusterPrefix + "-" + runID
+}
+
+// runIDFromPlatformClusterName returns the run ID a platform kind cluster has been created for
+func runIDFromPlatformClusterName(name string) string {
+	return strings.TrimPrefix(name, platformClusterPrefix+"-")
+}
+
+// isClusterOfPurpose returns true if the kind cluster has been created for the purpose in the run with the passed in ID,
+// i.e. it is named <purpose>-<runID> or <purpose>-<runID>-<suffix>
+func isClusterOfPurpose(clusterName string, purpose string, runID string) bool {
+	name := purpose + "-" + runID
+	return clusterName == name || strings.HasPrefix(clusterName, name+"-")
+}
+
+// listRunClusters and deleteKindCluster list and delete the kind clusters of a run
+var (
+	listRunClusters   = clusterutils.RunClusterNames
+	deleteKindCluster = clusterutils.DeleteCluster
+)
+
+// run tracks a test run so that its kind clusters can be deleted when the process is interrupted
+// or the run leaks clusters
+type run struct {
+	state    RunState
+	stateDir string
+	signals  chan os.Signal
+	done     chan struct{}
+}
+
+// startRun records the state of the run and deletes its kind clusters when the process receives
+// SIGINT or SIGTERM. A second signal terminates the process immediately.
+func startRun(runID string, keep bool) *run {
+	hostname, _ := os.Hostname()
+	r := &run{
+		state: RunState{
+			RunID:     runID,
+			PID:       os.Getpid(),
+			Hostname:  hostname,
+			StartedAt: time.Now(),
+			Keep:      keep,
+		},
+		stateDir: StateDir(""),
+		signals:  make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	if err := writeRunState(r.stateDir, r.state); err != nil {
+		klog.Errorf("record state of run %s failed: %v", runID, err)
+	}
+	signal.Notify(r.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-r.signals:
+			signal.Stop(r.signals)
+			klog.Warningf("received %s, tearing down run %s", sig, runID)
+			if err := r.teardown(); err != nil {
+				klog.Error(err)
+			}
+			klog.Flush()
+			os.Exit(1)
+		case <-r.done:
+		}
+	}()
+	return r
+}
+
+// finish stops the signal handling and deletes the kind clusters of the run that are still left,
+// unless the environment is kept for reuse. Left clusters of the passed in purposes, the clusters the teardown
+// of the environment deletes, are reported as leaks before they are deleted. Other clusters, e.g. workload and
+// MCP clusters created by the scheduler, are deleted without being reported.
+func (r *run) finish(cleanedUp ...string) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		signal.Stop(r.signals)
+		close(r.done)
+		leakErr := r.leaks(cleanedUp)
+		return ctx, errors.Join(leakErr, r.teardown())
+	}
+}
+
+// teardown deletes all kind clusters of the run. The run state is removed once all clusters are gone,
+// otherwise the run is marked as finished so that Sweep picks it up.
+func (r *run) teardown() error {
+	if r.state.Keep {
+		klog.Infof("keeping kind clusters of run %s for reuse", r.state.RunID)
+		return nil
+	}
+	clusters, err := listRunClusters(r.state.RunID)
+	if err != nil {
+		return r.markFinished(fmt.Errorf("list kind clusters of run %s failed: %w", r.state.RunID, err))
+	}
+	var errs []error
+	for _, name := range clusters {
+		klog.Infof("delete kind cluster %s", name)
+		if err := deleteKindCluster(name); err != nil {
+			errs = append(errs, fmt.Errorf("delete kind cluster %s failed: %w", name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return r.markFinished(err)
+	}
+	removeRunState(r.stateDir, r.state.RunID)
+	return nil
+}
+
+// leaks returns an error if kind clusters of the run with the passed in purposes are left
+func (r *run) leaks(purposes []string) error {
+	if r.state.Keep || len(purposes) == 0 {
+		return nil
+	}
+	clusters, err := listRunClusters(r.state.RunID)
+	if err != nil {
+		return err
+	}
+	clusters = slices.DeleteFunc(clusters, func(name string) bool {
+		return !slices.ContainsFunc(purposes, func(purpose string) bool {
+			return isClusterOfPurpose(name, purpose, r.state.RunID)
+		})
+	})
+	if len(clusters) > 0 {
+		return fmt.Errorf("leaked kind clusters of run %s: %s", r.state.RunID, strings.Join(clusters, ", "))
+	}
+	return nil
+}
+
+func (r *run) markFinished(err error) error {
+	r.state.Finished = true
+	if writeErr := writeRunState(r.stateDir, r.state); writeErr != nil {
+		klog.Errorf("record state of run %s failed: %v", r.state.RunID, writeErr)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package setup
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+func TestRunFinishReportsLeaks(t *testing.T) {
+	tests := []struct {
+		name        string
+		keep        bool
+		clusters    []string
+		wantDeleted []string
+		wantErr     string
+	}{
+		{
+			name:        "left clusters are reported and deleted",
+			clusters:    []string{"onboarding-abc", "platform-abc"},
+			wantDeleted: []string{"onboarding-abc", "platform-abc"},
+			wantErr:     "leaked kind clusters of run abc: onboarding-abc, platform-abc",
+		},
+		{
+			name:        "clusters the teardown doesn't delete are not reported",
+			clusters:    []string{"workload-abc", "mcp-abc-x1f2", "onboarding-abcd"},
+			wantDeleted: []string{"workload-abc", "mcp-abc-x1f2", "onboarding-abcd"},
+		},
+		{
+			name:        "only clusters the teardown deletes are reported",
+			clusters:    []string{"platform-abc", "workload-abc", "onboarding-abc-x1f2"},
+			wantDeleted: []string{"platform-abc", "workload-abc", "onboarding-abc-x1f2"},
+			wantErr:     "leaked kind clusters of run abc: platform-abc, onboarding-abc-x1f2",
+		},
+		{
+			name:        "no clusters left",
+			wantDeleted: []string{},
+		},
+		{
+			name:        "kept environment",
+			keep:        true,
+			clusters:    []string{"platform-abc"},
+			wantDeleted: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(StateDirEnvVar, t.TempDir())
+			clusters := slices.Clone(tt.clusters)
+			deleted := []string{}
+			listRunClusters = func(string) ([]string, error) { return slices.Clone(clusters), nil }
+			deleteKindCluster = func(name string) error {
+				deleted = append(deleted, name)
+				clusters = slices.DeleteFunc(clusters, func(c string) bool { return c == name })
+				return nil
+			}
+			defer func() {
+				listRunClusters = clusterutils.RunClusterNames
+				deleteKindCluster = clusterutils.DeleteCluster
+			}()
+			_, err := startRun("abc", tt.keep).finish(platformClusterPrefix, onboardingClusterPrefix)(context.Background(), envconf.New())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantDeleted, deleted)
+		})
+	}
+}
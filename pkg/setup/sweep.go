@@ -0,0 +1,188 @@
+package setup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/clusterutils"
+)
+
+// StateDirEnvVar sets the directory the state of test runs is recorded in
+const StateDirEnvVar = "OPENMCP_STATE_DIR"
+
+// RunState is the recorded state of a test run that is used to find leaked kind clusters
+type RunState struct {
+	RunID     string    `json:"runId"`
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"startedAt"`
+	// Keep is set if the environment of the run is kept for reuse
+	Keep bool `json:"keep,omitempty"`
+	// Finished is set if the run finished without deleting all of its kind clusters
+	Finished bool `json:"finished,omitempty"`
+}
+
+// SweepOptions configures Sweep
+type SweepOptions struct {
+	// StateDir is the directory run states are recorded in.
+	// If empty, the environment variable OPENMCP_STATE_DIR or a directory in the temp dir is used.
+	StateDir string
+	// DryRun only reports the kind clusters that would be deleted
+	DryRun bool
+	// IncludeKept also deletes environments that have been kept for reuse
+	IncludeKept bool
+	// IncludeUnknown also deletes platform clusters without a recorded run state and the clusters of their runs
+	IncludeUnknown bool
+}
+
+// StateDir returns the passed in directory or, if empty, the directory set by OPENMCP_STATE_DIR
+// or a directory in the temp dir
+func StateDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+	if dir = os.Getenv(StateDirEnvVar); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "openmcp-testing", "runs")
+}
+
+// Sweep deletes the kind clusters of runs that have finished or whose process is not alive anymore
+// and returns the names of the deleted clusters
+func Sweep(opts SweepOptions) ([]string, error) {
+	dir := StateDir(opts.StateDir)
+	states, err := readRunStates(dir)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := clusterutils.RunClusterNames("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+	deleted := []string{}
+	var errs []error
+	for _, id := range runsToSweep(states, clusters, opts, processAlive) {
+		runErrs := []error{}
+		for _, name := range runClusters(clusters, id) {
+			if opts.DryRun {
+				klog.Infof("would delete kind cluster %s of run %s", name, id)
+				deleted = append(deleted, name)
+				continue
+			}
+			klog.Infof("delete kind cluster %s of run %s", name, id)
+			if err := clusterutils.DeleteCluster(name); err != nil {
+				runErrs = append(runErrs, fmt.Errorf("delete kind cluster %s: %w", name, err))
+				continue
+			}
+			deleted = append(deleted, name)
+		}
+		if len(runErrs) == 0 && !opts.DryRun {
+			removeRunState(dir, id)
+		}
+		errs = append(errs, runErrs...)
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// runsToSweep returns the IDs of the runs whose kind clusters can be deleted
+func runsToSweep(states []RunState, clusters []string, opts SweepOptions, alive func(RunState) bool) []string {
+	ids := []string{}
+	known := map[string]bool{}
+	for _, state := range states {
+		known[state.RunID] = true
+		if state.Keep && !opts.IncludeKept {
+			continue
+		}
+		if state.Finished || !alive(state) {
+			ids = append(ids, state.RunID)
+		}
+	}
+	if opts.IncludeUnknown {
+		for _, name := range clusters {
+			if !strings.HasPrefix(name, platformClusterPrefix+"-") {
+				continue
+			}
+			if id := runIDFromPlatformClusterName(name); !known[id] {
+				known[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// runClusters returns the kind clusters of the run with the passed in ID
+func runClusters(clusters []string, id string) []string {
+	names := []string{}
+	for _, name := range clusters {
+		if clusterutils.BelongsToRun(name, id) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// processAlive returns true if the process of the run is still running. Runs of other hosts are considered alive.
+func processAlive(state RunState) bool {
+	if hostname, _ := os.Hostname(); hostname != state.Hostname {
+		return true
+	}
+	p, err := os.FindProcess(state.PID)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func readRunStates(dir string) ([]RunState, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	states := []RunState{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		state := RunState{}
+		if err := json.Unmarshal(data, &state); err != nil {
+			klog.Warningf("ignoring invalid run state %s: %v", entry.Name(), err)
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func writeRunState(dir string, state RunState) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, state.RunID+".json"), data, 0o644)
+}
+
+func removeRunState(dir string, runID string) {
+	if err := os.Remove(filepath.Join(dir, runID+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		klog.Errorf("remove run state of run %s failed: %v", runID, err)
+	}
+}
@@ -0,0 +1,56 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunsToSweep(t *testing.T) {
+	states := []RunState{
+		{RunID: "alive"},
+		{RunID: "dead"},
+		{RunID: "finished", Finished: true},
+		{RunID: "kept", Keep: true},
+	}
+	clusters := []string{"platform-alive", "platform-dead", "platform-kept", "platform-unknown", "onboarding-unknown"}
+	alive := func(state RunState) bool {
+		return state.RunID == "alive"
+	}
+	tests := []struct {
+		name string
+		opts SweepOptions
+		want []string
+	}{
+		{
+			name: "finished and dead runs",
+			want: []string{"dead", "finished"},
+		},
+		{
+			name: "include kept runs",
+			opts: SweepOptions{IncludeKept: true},
+			want: []string{"dead", "finished", "kept"},
+		},
+		{
+			name: "include unknown runs",
+			opts: SweepOptions{IncludeUnknown: true},
+			want: []string{"dead", "finished", "unknown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, runsToSweep(states, clusters, tt.opts, alive))
+		})
+	}
+}
+
+func TestSweepRunWithPrefixOfLiveRun(t *testing.T) {
+	states := []RunState{{RunID: "ab"}, {RunID: "abc"}}
+	clusters := []string{"platform-ab", "onboarding-ab", "mcp-ab-x1f2", "platform-abc", "onboarding-abc", "mcp-abc-x1f2"}
+	alive := func(state RunState) bool {
+		return state.RunID == "abc"
+	}
+	ids := runsToSweep(states, clusters, SweepOptions{}, alive)
+	assert.Equal(t, []string{"ab"}, ids)
+	assert.Equal(t, []string{"platform-ab", "onboarding-ab", "mcp-ab-x1f2"}, runClusters(clusters, ids[0]))
+}
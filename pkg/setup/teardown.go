@@ -0,0 +1,86 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/pkg/env"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+)
+
+// StrictTeardownEnvVar enables the strict teardown mode when set to a true value, see TeardownSetup.Strict
+const StrictTeardownEnvVar = "OPENMCP_STRICT_TEARDOWN"
+
+// TeardownSetup configures the teardown of the environment
+type TeardownSetup struct {
+	// Concurrent deletes components of the same kind concurrently, i.e. all service providers, then all
+	// platform services and all cluster providers after the onboarding cluster has been deleted.
+	// The number of concurrent deletions is limited by OpenMCPSetup.Concurrency.
+	Concurrent bool `json:"concurrent,omitempty"`
+	// Strict fails the run if the teardown fails or leaks kind clusters.
+	// Strict can also be enabled by setting the environment variable OPENMCP_STRICT_TEARDOWN=true.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// strictTeardown returns true if the strict teardown has been enabled either by field or by environment variable
+func (s *OpenMCPSetup) strictTeardown() bool {
+	return s.Teardown.Strict || boolFromEnv(StrictTeardownEnvVar)
+}
+
+// teardownLimit returns the number of teardown deletions that may run at a time
+func (s *OpenMCPSetup) teardownLimit() int {
+	if s.Teardown.Concurrent {
+		return s.Concurrency
+	}
+	return 1
+}
+
+// Run runs the test suite in the passed in test environment and returns the exit code. Teardown errors are
+// logged and, if strict teardown is enabled, result in a non-zero exit code.
+func (e *OpenMCPEnvironment) Run(testenv env.Environment, m *testing.M) int {
+	exitCode := testenv.Run(m)
+	err := e.TeardownError()
+	if err == nil {
+		return exitCode
+	}
+	klog.Errorf("teardown failed: %v", err)
+	if exitCode == 0 && e.setup.strictTeardown() {
+		return 1
+	}
+	return exitCode
+}
+
+// TeardownError returns all errors that occurred during the teardown of the environment joined in order
+func (e *OpenMCPEnvironment) TeardownError() error {
+	e.teardownMu.Lock()
+	defer e.teardownMu.Unlock()
+	return errors.Join(e.teardownErrs...)
+}
+
+// teardownStep records the error of a teardown step instead of only logging it
+func (e *OpenMCPEnvironment) teardownStep(name string, fn env.Func) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		ctx, err := fn(ctx, c)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			e.teardownMu.Lock()
+			e.teardownErrs = append(e.teardownErrs, err)
+			e.teardownMu.Unlock()
+		}
+		return ctx, err
+	}
+}
+
+// destroyPlatformCluster destroys the platform kind cluster if it has been created
+func destroyPlatformCluster(name string) env.Func {
+	return func(ctx context.Context, c *envconf.Config) (context.Context, error) {
+		if c.KubeconfigFile() == "" {
+			return ctx, nil
+		}
+		return envfuncs.DestroyCluster(name)(ctx, c)
+	}
+}
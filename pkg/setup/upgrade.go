@@ -0,0 +1,118 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/envfuncs"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+
+	"github.com/openmcp-project/openmcp-testing/pkg/providers"
+	"github.com/openmcp-project/openmcp-testing/pkg/resources"
+)
+
+// UpgradeOperator changes the image of the openmcp-operator in place and waits until the new image is rolled out
+// and the operator is available. If the operator image is loaded into the cluster, the new image is loaded as well.
+func (e *OpenMCPEnvironment) UpgradeOperator(ctx context.Context, image string) error {
+	operator := &e.setup.Operator
+	klog.Infof("upgrade operator %s to %s", operator.Name, image)
+	if operator.LoadImageToCluster {
+		var err error
+		if image, err = e.loadImage(ctx, image); err != nil {
+			return err
+		}
+	}
+	c := e.platform
+	deployment := &appsv1.Deployment{}
+	if err := c.Client().Resources().Get(ctx, operator.Name, operator.Namespace, deployment); err != nil {
+		return err
+	}
+	for i := range deployment.Spec.Template.Spec.InitContainers {
+		deployment.Spec.Template.Spec.InitContainers[i].Image = image
+	}
+	for i := range deployment.Spec.Template.Spec.Containers {
+		deployment.Spec.Template.Spec.Containers[i].Image = image
+	}
+	if err := c.Client().Resources().Update(ctx, deployment); err != nil {
+		return fmt.Errorf("failed to update operator image: %w", err)
+	}
+	if err := resources.WaitForRollout(ctx, c, image, operator.WaitOpts...); err != nil {
+		return err
+	}
+	if err := wait.For(conditions.New(c.Client().Resources()).
+		DeploymentAvailable(operator.Name, operator.Namespace), operator.WaitOpts...); err != nil {
+		return err
+	}
+	operator.Image = image
+	return nil
+}
+
+// UpgradeServiceProvider changes the image of an installed service provider in place and waits until the new image
+// is rolled out and the service provider is ready. If the service provider image is loaded into the cluster,
+// the new image is loaded as well.
+func (e *OpenMCPEnvironment) UpgradeServiceProvider(ctx context.Context, name string, image string) error {
+	for i := range e.setup.ServiceProviders {
+		sp := &e.setup.ServiceProviders[i]
+		if sp.Name != name {
+			continue
+		}
+		if sp.LoadImageToCluster {
+			var err error
+			if image, err = e.loadImage(ctx, image); err != nil {
+				return err
+			}
+		}
+		upgraded := *sp
+		upgraded.Image = image
+		if err := providers.UpgradeServiceProvider(ctx, e.platform, upgraded); err != nil {
+			return err
+		}
+		sp.Image = image
+		return nil
+	}
+	return fmt.Errorf("service provider %s is not part of the setup", name)
+}
+
+// loadImage makes a local image available in the clusters of the run and returns the image reference to use
+func (e *OpenMCPEnvironment) loadImage(ctx context.Context, image string) (string, error) {
+	if e.setup.Registry.Enabled {
+		err := e.registry.push(ctx, 1, []*string{&image})
+		return image, err
+	}
+	_, err := envfuncs.LoadDockerImageToCluster(e.PlatformClusterName, image)(ctx, e.platform)
+	return image, err
+}
+
+// UpgradeOperator returns a feature step that upgrades the openmcp-operator of the environment to the passed in image
+func UpgradeOperator(image string) features.Func {
+	return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		e, ok := EnvironmentFromContext(ctx)
+		if !ok {
+			t.Fatal("no openMCP environment found in context")
+		}
+		if err := e.UpgradeOperator(ctx, image); err != nil {
+			t.Errorf("upgrade operator failed: %v", err)
+		}
+		return ctx
+	}
+}
+
+// UpgradeServiceProvider returns a feature step that upgrades a service provider of the environment to the passed in image
+func UpgradeServiceProvider(name string, image string) features.Func {
+	return func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+		e, ok := EnvironmentFromContext(ctx)
+		if !ok {
+			t.Fatal("no openMCP environment found in context")
+		}
+		if err := e.UpgradeServiceProvider(ctx, name, image); err != nil {
+			t.Errorf("upgrade service provider %s failed: %v", name, err)
+		}
+		return ctx
+	}
+}